@@ -0,0 +1,361 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"net/url"
+	"reflect"
+)
+
+// OptionsProvider computes the options of a choice widget each time
+// the widget is rendered or filled, e.g. by loading them from a
+// database.
+type OptionsProvider interface {
+	// SelectOptions returns the current options. The message of a
+	// returned error is added to the widget's errors.
+	SelectOptions() ([]SelectOption, error)
+}
+
+// OptionsFunc is a function implementing the OptionsProvider interface.
+type OptionsFunc func() ([]SelectOption, error)
+
+// SelectOptions calls f().
+func (f OptionsFunc) SelectOptions() ([]SelectOption, error) {
+	return f()
+}
+
+// SelectOptionGroup is a group of consecutive options with the same
+// group label.
+type SelectOptionGroup struct {
+	// Label is the group label, empty for options without a group.
+	Label   string
+	Options []GroupedOption
+}
+
+// GroupedOption is an option of a SelectOptionGroup.
+type GroupedOption struct {
+	SelectOption
+	// Disabled options are rendered, but can't be chosen.
+	Disabled bool
+}
+
+// GroupOptions returns the options of the given render data of a
+// choice widget in groups. These are its OptionGroups or, if there are
+// none, a single group without label containing the options in Data.
+func GroupOptions(rd WidgetRenderData) []SelectOptionGroup {
+	if rd.OptionGroups != nil {
+		return rd.OptionGroups
+	}
+	options, _ := rd.Data.([]SelectOption)
+	if len(options) == 0 {
+		return nil
+	}
+	group := SelectOptionGroup{}
+	for _, option := range options {
+		group.Options = append(group.Options, GroupedOption{
+			SelectOption: option})
+	}
+	return []SelectOptionGroup{group}
+}
+
+// groupOptions groups consecutive options with the same group label
+// in groups, translating the labels, and marks the disabled ones. It
+// returns nil if there are neither groups nor disabled options.
+func (f *Form) groupOptions(options []SelectOption, groups map[string]string,
+	disabled []string) []SelectOptionGroup {
+	if len(groups) == 0 && len(disabled) == 0 {
+		return nil
+	}
+	var result []SelectOptionGroup
+	for _, option := range f.translateOptions(options) {
+		label := f.translate(groups[option.Value], nil)
+		if len(result) == 0 || result[len(result)-1].Label != label {
+			result = append(result, SelectOptionGroup{Label: label})
+		}
+		last := &result[len(result)-1]
+		last.Options = append(last.Options, GroupedOption{
+			SelectOption: option,
+			Disabled:     isDisabled(disabled, option.Value),
+		})
+	}
+	return result
+}
+
+// isDisabled returns true if value is one of the disabled values.
+func isDisabled(disabled []string, value string) bool {
+	for _, d := range disabled {
+		if d == value {
+			return true
+		}
+	}
+	return false
+}
+
+// currentOptions returns the options computed by provider or the
+// static options if provider is nil.
+func currentOptions(options []SelectOption, provider OptionsProvider) (
+	[]SelectOption, error) {
+	if provider == nil {
+		return options, nil
+	}
+	return provider.SelectOptions()
+}
+
+// validOption returns true if value is the value of one of the options
+// and not disabled.
+func validOption(options []SelectOption, disabled []string,
+	value string) bool {
+	for _, option := range options {
+		if option.Value == value && !isDisabled(disabled, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// markSelected returns a copy of the options with only the options
+// having one of the given values selected.
+func markSelected(options []SelectOption, values ...string) []SelectOption {
+	chosen := make(map[string]bool, len(values))
+	for _, value := range values {
+		chosen[value] = true
+	}
+	marked := make([]SelectOption, len(options))
+	for i, option := range options {
+		option.Selected = chosen[option.Value]
+		marked[i] = option
+	}
+	return marked
+}
+
+// MultiSelectWidget allows to choose any number of multiple options.
+//
+// The values of all chosen options are converted by the Codec to the
+// element type of the target slice. If the type of the target field
+// can't be determined, a []string is filled in.
+//
+// It is rendered with the "multiselect" template or the "checkboxes"
+// template if Checkboxes is set. The Data of the render data contains
+// the options with Selected set for the chosen ones.
+type MultiSelectWidget struct {
+	WidgetBase
+	Options []SelectOption
+	// Provider computes the options if set. Options is ignored then.
+	Provider OptionsProvider
+	// Groups and Disabled group and disable options like those of
+	// SelectWidget.
+	Groups   map[string]string
+	Disabled []string
+	// Checkboxes renders the options as a group of checkboxes instead
+	// of a select element.
+	Checkboxes bool
+	// MinChoices and MaxChoices limit the number of options to choose.
+	// Zero means no limit.
+	MinChoices, MaxChoices int
+	// MinChoicesError, MaxChoicesError and InvalidError are the messages
+	// if too few or too many options are chosen or a value is not one
+	// of the options. If empty, default messages are used.
+	MinChoicesError, MaxChoicesError, InvalidError string
+	// Codec converts between option values and the values of the app
+	// struct. If nil, DefaultCodec is used.
+	Codec Codec
+	// filled is true if the widget has been filled.
+	filled bool
+}
+
+var stringSliceType = reflect.TypeOf([]string{})
+
+func (w *MultiSelectWidget) GetRenderData() WidgetRenderData {
+	rd := WidgetRenderData{
+		WidgetBase: w.WidgetBase,
+		Template:   "multiselect"}
+	if w.Checkboxes {
+		rd.Template = "checkboxes"
+	}
+	options, err := currentOptions(w.Options, w.Provider)
+	if err != nil {
+		rd.Errors = append(rd.Errors, err.Error())
+		return rd
+	}
+	value, err := w.form.getNestedField(w.Id)
+	if err != nil {
+		panic(err)
+	}
+	if value.IsValid() && (w.filled || value.Len() > 0) {
+		var chosen []string
+		for i := 0; i < value.Len(); i++ {
+			encoded, err := codecOrDefault(w.Codec).Encode(value.Index(i))
+			if err == nil {
+				chosen = append(chosen, encoded)
+			}
+		}
+		options = markSelected(options, chosen...)
+	}
+	rd.Data = w.form.translateOptions(options)
+	rd.OptionGroups = w.form.groupOptions(options, w.Groups, w.Disabled)
+	return rd
+}
+
+func (w *MultiSelectWidget) Fill(values url.Values) bool {
+	w.Errors = nil
+	w.filled = true
+	options, err := currentOptions(w.Options, w.Provider)
+	if err != nil {
+		w.Errors = append(w.Errors, err.Error())
+		return false
+	}
+	valid := true
+	target := w.form.targetType(w.Id, stringSliceType)
+	if target.Kind() != reflect.Slice {
+		panic(fieldError(w.Id, ErrTypeMismatch,
+			"MultiSelectWidget needs a slice, got %v", target))
+	}
+	submitted := make(map[string]bool)
+	for _, value := range values[w.Id] {
+		submitted[value] = true
+	}
+	chosen := reflect.MakeSlice(target, 0, len(submitted))
+	for _, option := range options {
+		if !submitted[option.Value] || isDisabled(w.Disabled, option.Value) {
+			continue
+		}
+		value, err := codecOrDefault(w.Codec).Decode(option.Value, target.Elem())
+		if err != nil {
+			continue
+		}
+		delete(submitted, option.Value)
+		chosen = reflect.Append(chosen, value)
+	}
+	if len(submitted) > 0 {
+		w.addError(w.InvalidError, "htmlwidgets.invalid-option", nil)
+		valid = false
+	}
+	if w.MinChoices > 0 && chosen.Len() < w.MinChoices {
+		w.addError(w.MinChoicesError, "htmlwidgets.min-choices",
+			map[string]interface{}{"min": w.MinChoices})
+		valid = false
+	}
+	if w.MaxChoices > 0 && chosen.Len() > w.MaxChoices {
+		w.addError(w.MaxChoicesError, "htmlwidgets.max-choices",
+			map[string]interface{}{"max": w.MaxChoices})
+		valid = false
+	}
+	w.form.setField(w.Id, chosen.Interface())
+	return w.validate(chosen.Interface()) && valid
+}
+
+func (w *MultiSelectWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	if t.Kind() != reflect.Slice {
+		return FieldErrors{fieldError(id, ErrTypeMismatch,
+			"MultiSelectWidget needs a slice, got %v", t)}
+	}
+	return verifyCodec(id, w.Codec, t.Elem())
+}
+
+// RadioWidget allows to choose one from multiple options rendered as
+// radio buttons.
+//
+// The value of the chosen option is converted by the Codec to the type
+// of the target field. If the type can't be determined, a string is
+// filled in.
+//
+// Unlike SelectWidget, nothing is chosen if no value is submitted. In
+// this case the zero value is filled in and the validators are run
+// with nil, so use Required to enforce a choice.
+//
+// It is rendered with the "radio" template. The Data of the render
+// data contains the options with Selected set for the chosen one.
+type RadioWidget struct {
+	WidgetBase
+	Options []SelectOption
+	// Provider computes the options if set. Options is ignored then.
+	Provider OptionsProvider
+	// Groups and Disabled group and disable options like those of
+	// SelectWidget.
+	Groups   map[string]string
+	Disabled []string
+	// InvalidError is the message if a value is not one of the enabled
+	// options. If empty, a default message is used.
+	InvalidError string
+	// Codec converts between option values and the values of the app
+	// struct. If nil, DefaultCodec is used.
+	Codec Codec
+	// filled is true if the widget has been filled.
+	filled bool
+	// chosen is the value of the option chosen by the last fill or nil
+	// if none has been chosen.
+	chosen *string
+}
+
+var stringType = reflect.TypeOf("")
+
+func (w *RadioWidget) GetRenderData() WidgetRenderData {
+	rd := WidgetRenderData{
+		WidgetBase: w.WidgetBase,
+		Template:   "radio"}
+	options, err := currentOptions(w.Options, w.Provider)
+	if err != nil {
+		rd.Errors = append(rd.Errors, err.Error())
+		return rd
+	}
+	value, err := w.form.getNestedField(w.Id)
+	if err != nil {
+		panic(err)
+	}
+	switch {
+	case w.filled && w.chosen != nil:
+		options = markSelected(options, *w.chosen)
+	case w.filled:
+		options = markSelected(options)
+	case value.IsValid() && !isEmpty(value.Interface()):
+		if encoded, err := codecOrDefault(w.Codec).Encode(value); err == nil {
+			options = markSelected(options, encoded)
+		}
+	}
+	rd.Data = w.form.translateOptions(options)
+	rd.OptionGroups = w.form.groupOptions(options, w.Groups, w.Disabled)
+	return rd
+}
+
+func (w *RadioWidget) Fill(values url.Values) bool {
+	w.Errors = nil
+	w.filled = true
+	w.chosen = nil
+	options, err := currentOptions(w.Options, w.Provider)
+	if err != nil {
+		w.Errors = append(w.Errors, err.Error())
+		return false
+	}
+	target := w.form.targetType(w.Id, stringType)
+	submitted := values.Get(w.Id)
+	if submitted == "" {
+		w.form.clearField(w.Id, stringType)
+		return w.validate(nil)
+	}
+	value, err := codecOrDefault(w.Codec).Decode(submitted, target)
+	if !validOption(options, w.Disabled, submitted) || err != nil {
+		w.addError(w.InvalidError, "htmlwidgets.invalid-option", nil)
+		return false
+	}
+	w.chosen = &submitted
+	w.form.setField(w.Id, value.Interface())
+	return w.validate(value.Interface())
+}
+
+func (w *RadioWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	return verifyCodec(id, w.Codec, t)
+}
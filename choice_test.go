@@ -0,0 +1,270 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type TestMultiSelectWidgetData struct {
+	Id []string
+}
+
+func TestMultiSelectWidget(t *testing.T) {
+	testWidget(t, &WidgetTest{
+		Widget: &MultiSelectWidget{Options: []SelectOption{
+			SelectOption{"foo", "Foo", true},
+			SelectOption{"bar", "Bar", false},
+		}},
+		AppStruct:   &TestMultiSelectWidgetData{},
+		URLValue:    "bar",
+		FilledValue: []string{"bar"},
+		EmptyValue:  []string{},
+		RenderData: []SelectOption{
+			SelectOption{"foo", "Foo", false},
+			SelectOption{"bar", "Bar", true},
+		},
+		Template: "multiselect",
+	})
+	testWidget(t, &WidgetTest{
+		Widget: &MultiSelectWidget{Checkboxes: true, Options: []SelectOption{
+			SelectOption{"foo", "Foo", false},
+		}},
+		AppStruct:   &TestMultiSelectWidgetData{},
+		URLValue:    "cruz",
+		FilledValue: []string{},
+		EmptyValue:  []string{},
+		RenderData: []SelectOption{
+			SelectOption{"foo", "Foo", false},
+		},
+		Error:    "Please choose a valid option.",
+		Template: "checkboxes",
+	})
+}
+
+type TestMultiSelectColor string
+
+type TestMultiSelectTypedData struct {
+	Colors []TestMultiSelectColor
+	Ids    []int
+}
+
+func TestMultiSelectWidgetTyped(t *testing.T) {
+	data := TestMultiSelectTypedData{Ids: []int{2}}
+	form := NewForm(&data)
+	form.AddWidget(&MultiSelectWidget{Options: []SelectOption{
+		SelectOption{"red", "Red", false},
+		SelectOption{"green", "Green", false},
+		SelectOption{"blue", "Blue", false},
+	}}, "Colors", "", "")
+	ids := &MultiSelectWidget{
+		Options: []SelectOption{
+			SelectOption{"1", "One", false},
+			SelectOption{"2", "Two", false},
+			SelectOption{"3", "Three", false},
+		},
+		MinChoices:      1,
+		MaxChoices:      2,
+		MinChoicesError: "Too few!",
+	}
+	form.AddWidget(ids, "Ids", "", "")
+
+	renderData := form.RenderData()
+	if !renderData.Widgets[1].Data.([]SelectOption)[1].Selected {
+		t.Errorf("Option of initial data not selected: %v",
+			renderData.Widgets[1].Data)
+	}
+
+	vals := url.Values{
+		"Colors": []string{"blue", "red"},
+		"Ids":    []string{"3", "1"},
+	}
+	if !form.Fill(vals) {
+		t.Errorf("Fill returned false. Errors: %v", ids.Errors)
+	}
+	expected := TestMultiSelectTypedData{
+		Colors: []TestMultiSelectColor{"red", "blue"},
+		Ids:    []int{1, 3},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("Filled data is %#v, expected %#v", data, expected)
+	}
+
+	vals["Ids"] = []string{"1", "2", "3"}
+	if form.Fill(vals) || !reflect.DeepEqual(ids.Errors,
+		[]string{"Please choose at most 2 options."}) {
+		t.Errorf("Fill with too many choices has errors %v", ids.Errors)
+	}
+	delete(vals, "Ids")
+	if form.Fill(vals) || !reflect.DeepEqual(ids.Errors, []string{"Too few!"}) {
+		t.Errorf("Fill with too few choices has errors %v", ids.Errors)
+	}
+}
+
+type TestRadioWidgetData struct {
+	Id string
+}
+
+func TestRadioWidget(t *testing.T) {
+	testWidget(t, &WidgetTest{
+		Widget: &RadioWidget{Options: []SelectOption{
+			SelectOption{"foo", "Foo", false},
+			SelectOption{"bar", "Bar", false},
+		}},
+		AppStruct:   &TestRadioWidgetData{},
+		URLValue:    "bar",
+		FilledValue: "bar",
+		EmptyValue:  "",
+		RenderData: []SelectOption{
+			SelectOption{"foo", "Foo", false},
+			SelectOption{"bar", "Bar", true},
+		},
+		Template: "radio",
+	})
+	testWidget(t, &WidgetTest{
+		Widget: &RadioWidget{Options: []SelectOption{
+			SelectOption{"foo", "Foo", false},
+		}, InvalidError: "Invalid!"},
+		AppStruct:   &TestRadioWidgetData{},
+		URLValue:    "cruz",
+		FilledValue: "",
+		EmptyValue:  "",
+		RenderData: []SelectOption{
+			SelectOption{"foo", "Foo", false},
+		},
+		Error:    "Invalid!",
+		Template: "radio",
+	})
+}
+
+type TestRadioWidgetTypedData struct {
+	Level int
+}
+
+func TestRadioWidgetRequired(t *testing.T) {
+	data := TestRadioWidgetTypedData{Level: 1}
+	form := NewForm(&data)
+	widget := &RadioWidget{Options: []SelectOption{
+		SelectOption{"0", "Zero", false},
+		SelectOption{"1", "One", false},
+	}}
+	widget.Validators = []Validator{Required("Choose!")}
+	form.AddWidget(widget, "Level", "", "")
+	options := form.RenderData().Widgets[0].Data.([]SelectOption)
+	if options[0].Selected || !options[1].Selected {
+		t.Errorf("Initial data not selected: %v", options)
+	}
+	if form.Fill(url.Values{}) {
+		t.Errorf("Fill without choice returned true")
+	}
+	if !reflect.DeepEqual(widget.Errors, []string{"Choose!"}) {
+		t.Errorf("Errors are %v, expected [Choose!]", widget.Errors)
+	}
+	for _, option := range form.RenderData().Widgets[0].Data.([]SelectOption) {
+		if option.Selected {
+			t.Errorf("Option %q selected without choice", option.Value)
+		}
+	}
+	if !form.Fill(url.Values{"Level": []string{"0"}}) {
+		t.Errorf("Fill with first option returned false. Errors: %v",
+			widget.Errors)
+	}
+	if data.Level != 0 {
+		t.Errorf("Filled level is %v, expected 0", data.Level)
+	}
+	options = form.RenderData().Widgets[0].Data.([]SelectOption)
+	if !options[0].Selected || options[1].Selected {
+		t.Errorf("First option not selected: %v", options)
+	}
+}
+
+func TestRadioWidgetPointer(t *testing.T) {
+	zero := 0
+	data := struct{ Level *int }{&zero}
+	form := NewForm(&data)
+	form.AddWidget(&RadioWidget{Options: []SelectOption{
+		SelectOption{"0", "Zero", false},
+		SelectOption{"1", "One", false},
+	}}, "Level", "", "")
+	form.Fill(url.Values{})
+	if data.Level != nil {
+		t.Errorf("Fill without choice set level to %v", *data.Level)
+	}
+	form.Fill(url.Values{"Level": []string{"0"}})
+	if data.Level == nil || *data.Level != 0 {
+		t.Errorf("Filled level is %v, expected 0", data.Level)
+	}
+}
+
+func TestGroupOptions(t *testing.T) {
+	options := []SelectOption{
+		SelectOption{"a", "A", false},
+		SelectOption{"b", "B", true},
+		SelectOption{"c", "C", false},
+		SelectOption{"d", "D", false},
+		SelectOption{"e", "E", false},
+	}
+	form := NewForm(&TestRadioWidgetData{})
+	groups := map[string]string{"b": "X", "c": "X", "d": "Y"}
+	expected := []SelectOptionGroup{
+		{"", []GroupedOption{{options[0], false}}},
+		{"X", []GroupedOption{{options[1], false}, {options[2], true}}},
+		{"Y", []GroupedOption{{options[3], false}}},
+		{"", []GroupedOption{{options[4], false}}},
+	}
+	result := form.groupOptions(options, groups, []string{"c"})
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("groupOptions returned %v, expected %v", result, expected)
+	}
+	if result := form.groupOptions(options, nil, nil); result != nil {
+		t.Errorf("groupOptions without groups returned %v", result)
+	}
+	rd := WidgetRenderData{Data: options[:2]}
+	if result := GroupOptions(rd); !reflect.DeepEqual(result,
+		[]SelectOptionGroup{{"", []GroupedOption{{options[0], false},
+			{options[1], false}}}}) {
+		t.Errorf("GroupOptions returned %v", result)
+	}
+	rd.OptionGroups = expected
+	if result := GroupOptions(rd); !reflect.DeepEqual(result, expected) {
+		t.Errorf("GroupOptions ignored the OptionGroups: %v", result)
+	}
+}
+
+func TestChoiceWidgetsDisabledOptions(t *testing.T) {
+	options := []SelectOption{
+		SelectOption{"foo", "Foo", false},
+		SelectOption{"bar", "Bar", false},
+	}
+	disabled := []string{"bar"}
+	data := TestMultiSelectWidgetData{}
+	form := NewForm(&data)
+	form.AddWidget(&MultiSelectWidget{Options: options, Disabled: disabled},
+		"Id", "", "")
+	if form.Fill(url.Values{"Id": []string{"foo", "bar"}}) {
+		t.Errorf("MultiSelectWidget accepted disabled option")
+	}
+	radioData := TestRadioWidgetData{}
+	form = NewForm(&radioData)
+	form.AddWidget(&RadioWidget{Options: options, Disabled: disabled}, "Id",
+		"", "")
+	if form.Fill(url.Values{"Id": []string{"bar"}}) || radioData.Id != "" {
+		t.Errorf("RadioWidget accepted disabled option")
+	}
+}
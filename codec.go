@@ -0,0 +1,139 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Codec converts between the option values of choice widgets (e.g.
+// SelectWidget) and the values of the app struct.
+type Codec interface {
+	// Decode converts the option value s to a value of type t.
+	Decode(s string, t reflect.Type) (reflect.Value, error)
+	// Encode converts the given value of the app struct to an option
+	// value.
+	Encode(v reflect.Value) (string, error)
+}
+
+// DefaultCodec is used by choice widgets without a Codec.
+//
+// It supports all string, bool, int, uint and float types as well as
+// types implementing encoding.TextUnmarshaler and
+// encoding.TextMarshaler.
+var DefaultCodec Codec = defaultCodec{}
+
+type defaultCodec struct{}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func (defaultCodec) Decode(s string, t reflect.Type) (reflect.Value, error) {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		value := reflect.New(t)
+		err := value.Interface().(encoding.TextUnmarshaler).UnmarshalText(
+			[]byte(s))
+		return value.Elem(), err
+	}
+	switch t.Kind() {
+	case reflect.String:
+		value := reflect.New(t).Elem()
+		value.SetString(s)
+		return value, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		value := reflect.New(t).Elem()
+		value.SetBool(b)
+		return value, err
+	}
+	value, _, err := parseNumber(s, t)
+	return value, err
+}
+
+func (defaultCodec) Encode(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		if v.Type().Implements(textMarshalerType) {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.Type().Implements(textMarshalerType) && v.CanAddr() &&
+		v.Addr().Type().Implements(textMarshalerType) {
+		v = v.Addr()
+	}
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	switch v.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16,
+		reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32,
+		reflect.Float64:
+		return formatNumber(v), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	}
+	return "", fmt.Errorf("htmlwidgets: Can't encode values of type %v",
+		v.Type())
+}
+
+// verifyCodec checks that codec can decode values for a field of type
+// t. Codecs other than DefaultCodec can't be checked and are trusted.
+func verifyCodec(id string, codec Codec, t reflect.Type) FieldErrors {
+	if codec != nil && codec != DefaultCodec {
+		return nil
+	}
+	t = indirectType(t)
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) ||
+		t.Kind() == reflect.Bool || isNumberKind(t.Kind()) {
+		return nil
+	}
+	return FieldErrors{fieldError(id, ErrTypeMismatch,
+		"DefaultCodec can't decode values of type %v", t)}
+}
+
+// codecOrDefault returns the given codec or DefaultCodec if it's nil.
+func codecOrDefault(codec Codec) Codec {
+	if codec == nil {
+		return DefaultCodec
+	}
+	return codec
+}
+
+// targetType returns the type of the field with the given id without
+// pointer indirection. If the type can't be determined, defaultType is
+// returned.
+func (f *Form) targetType(id string, defaultType reflect.Type) reflect.Type {
+	field, err := f.getNestedField(id)
+	if err != nil || !field.IsValid() {
+		return defaultType
+	}
+	t := field.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
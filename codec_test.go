@@ -0,0 +1,157 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testColor string
+
+// testLevel implements encoding.TextMarshaler and TextUnmarshaler.
+type testLevel int
+
+func (l testLevel) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("level-%d", l)), nil
+}
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	s := string(text)
+	if !strings.HasPrefix(s, "level-") {
+		return fmt.Errorf("invalid level %q", s)
+	}
+	_, err := fmt.Sscan(s[len("level-"):], (*int)(l))
+	return err
+}
+
+func TestDefaultCodec(t *testing.T) {
+	tests := []struct {
+		Text  string
+		Value interface{}
+		Error bool
+	}{
+		{"foo", "foo", false},
+		{"red", testColor("red"), false},
+		{"-12", int(-12), false},
+		{"12", uint8(12), false},
+		{"300", uint8(0), true},
+		{"1.5", float64(1.5), false},
+		{"true", true, false},
+		{"level-3", testLevel(3), false},
+		{"3", testLevel(0), true},
+	}
+	for _, test := range tests {
+		value, err := DefaultCodec.Decode(test.Text,
+			reflect.TypeOf(test.Value))
+		if test.Error {
+			if err == nil {
+				t.Errorf("Decoding %q into %T should fail", test.Text, test.Value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Decoding %q into %T failed: %v", test.Text, test.Value, err)
+			continue
+		}
+		if !reflect.DeepEqual(value.Interface(), test.Value) {
+			t.Errorf("Decoded %q into %#v, expected %#v", test.Text,
+				value.Interface(), test.Value)
+		}
+		text, err := DefaultCodec.Encode(reflect.ValueOf(test.Value))
+		if err != nil || text != test.Text {
+			t.Errorf("Encoded %#v into %q (%v), expected %q", test.Value, text,
+				err, test.Text)
+		}
+	}
+	level := testLevel(2)
+	if text, _ := DefaultCodec.Encode(reflect.ValueOf(&level)); text != "level-2" {
+		t.Errorf("Encoded pointer into %q, expected %q", text, "level-2")
+	}
+	if _, err := DefaultCodec.Encode(reflect.ValueOf(struct{}{})); err == nil {
+		t.Errorf("Encoding a struct should fail")
+	}
+}
+
+type TestCodecData struct {
+	Category int
+	Color    testColor
+	Level    *testLevel
+	Levels   []testLevel
+	Ids      []uint
+}
+
+func TestChoiceWidgetsCodec(t *testing.T) {
+	data := TestCodecData{}
+	form := NewForm(&data)
+	form.AddWidget(&SelectWidget{Options: []SelectOption{
+		SelectOption{Value: "1", Description: "One"},
+		SelectOption{Value: "2", Description: "Two"},
+	}}, "Category", "", "")
+	form.AddWidget(&RadioWidget{Options: []SelectOption{
+		SelectOption{Value: "red", Description: "Red"},
+		SelectOption{Value: "blue", Description: "Blue"},
+	}}, "Color", "", "")
+	form.AddWidget(&SelectWidget{Options: []SelectOption{
+		SelectOption{Value: "level-1", Description: "Low"},
+		SelectOption{Value: "level-2", Description: "High"},
+	}}, "Level", "", "")
+	form.AddWidget(&MultiSelectWidget{Options: []SelectOption{
+		SelectOption{Value: "level-1", Description: "Low"},
+		SelectOption{Value: "level-2", Description: "High"},
+	}}, "Levels", "", "")
+	form.AddWidget(&MultiSelectWidget{Options: []SelectOption{
+		SelectOption{Value: "7", Description: "Seven"},
+		SelectOption{Value: "x", Description: "Invalid"},
+	}}, "Ids", "", "")
+	valid := form.Fill(url.Values{
+		"Category": []string{"2"},
+		"Color":    []string{"blue"},
+		"Level":    []string{"level-2"},
+		"Levels":   []string{"level-1", "level-2"},
+		"Ids":      []string{"7"},
+	})
+	if !valid {
+		t.Fatalf("Form should be valid: %v", form.RenderData().Errors)
+	}
+	level := testLevel(2)
+	expected := TestCodecData{
+		Category: 2,
+		Color:    "blue",
+		Level:    &level,
+		Levels:   []testLevel{1, 2},
+		Ids:      []uint{7},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("Filled data is\n%#v\nexpected\n%#v", data, expected)
+	}
+	rd := form.RenderData()
+	for i, selected := range []string{"2", "blue", "level-2"} {
+		for _, option := range rd.Widgets[i].Data.([]SelectOption) {
+			if option.Selected != (option.Value == selected) {
+				t.Errorf("Option %q of %q has Selected %v", option.Value,
+					rd.Widgets[i].Id, option.Selected)
+			}
+		}
+	}
+	if form.Fill(url.Values{"Ids": []string{"x"}}) {
+		t.Errorf("Form should be invalid for values the codec can't decode")
+	}
+}
@@ -24,25 +24,38 @@ For each form, you have to define a struct for the form fields:
 	}
 
 Then you can use the form like this:
-	func handle(req Request, res *Response) {
+	func handle(w http.ResponseWriter, req *http.Request) {
 		data := formData{}
-		form := form.NewForm(&data, form.Fields{
-			"Name": form.Field{G("Name"), "Your Name", form.Required(G("Required.")), nil},
-			"Age": form.Field{G("Age"), "Your Age",
-			form.Required(G("Required.")), nil}})
+		form := htmlwidgets.NewForm(&data)
+		name := form.AddWidget(new(htmlwidgets.TextWidget), "Name", "Name", "Your Name")
+		name.Base().Validators = []htmlwidgets.Validator{htmlwidgets.Required("")}
+		form.AddWidget(new(htmlwidgets.IntegerWidget), "Age", "Age", "Your Age")
 		switch req.Method {
 		case "GET":
 			data.Name = "Default Name"
 		case "POST":
-			if form.Fill(req.GetFormData()) {
+			req.ParseForm()
+			if form.Fill(req.PostForm) {
 				save(data.Name, data.Age)
 			}
-		default:
-			panic()
 		}
-		fmt.Fprint(res, renderTemplate(form.RenderData()))
+		renderTemplate(w, form.RenderData())
 	}
 
+Values are validated by the Validators of each widget:
+	name := new(htmlwidgets.TextWidget)
+	name.Validators = []htmlwidgets.Validator{
+		htmlwidgets.Required("Required."), htmlwidgets.MaxLength(50, "")}
+	form.AddWidget(name, "Name", "Name", "Your Name")
+
+Instead of adding each widget by hand, the widgets may be derived from
+struct tags of the data struct:
+	type formData struct {
+		Name string `htmlwidgets:"label=Name,required,error=Required."`
+		Age  int    `htmlwidgets:"label=Age"`
+	}
+	form := htmlwidgets.NewFormFromStruct(&data)
+
 Fill the render data into a form template like this (html/template):
 	<form action="{{.Action}}" method="POST" accept-charset="utf-8" {{.EncTypeAttr}}>
 		<fieldset>
@@ -62,10 +75,74 @@ Fill the render data into a form template like this (html/template):
 			{{end}}
 			<div class="control-group">
 				<div class="controls">
-					<button type="submit" class="btn btn-primary">{{G "Submit"}}</button>
+					<button type="submit" class="btn btn-primary">Submit</button>
 				</div>
 			</div>
 		</fieldset>
 	</form>
+
+Alternatively, a Renderer renders a form with built-in templates for all
+widgets. Single templates may be replaced by their id:
+	renderer := htmlwidgets.NewRenderer()
+	renderer.SetTemplate("text", `<input class="input" name="{{.Id}}" value="{{.Data}}">`)
+	err := renderer.Render(w, form.RenderData())
+
+FillRequest fills a form with the values of a *http.Request. It also
+binds the files uploaded to a FileWidget:
+	result, err := form.FillRequest(req)
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if result.Valid && len(result.Actions) == 0 {
+		save(data.Avatar.Data)
+	}
+
+NewForm, Fill and RenderData panic if the widgets don't match the
+data, e.g. because of a misspelled widget id. NewFormE, FillE and
+RenderDataE report these mistakes as FieldErrors instead, as does
+FillRequest. Form.Verify finds most of them at startup by checking the
+widget ids against the type of the data:
+	if err := form.Verify(); err != nil {
+		log.Fatal(err)
+	}
+
+Labels, descriptions, options, the SubmitLabel and messages are
+translated to the Locale of the form by its Translator, e.g. a
+Catalog. Texts it doesn't translate are looked up in DefaultCatalog,
+which contains English and German messages for all built-in validators
+and widgets:
+	form.Locale = htmlwidgets.RequestLocale(req, "en", "de")
+	form.Translator = htmlwidgets.Catalog{"de": {
+		"Name":                 "Name",
+		"Your Name":            "Ihr Name",
+		"{label} is too short": "{label} ist zu kurz",
+	}}
+If LocalizeInputs is set and the locale has a LocaleFormat, numbers,
+dates and times are parsed and rendered in its format, e.g. "1.234,5"
+and "16.10.2026 14:30" for "de", and rendered as text inputs. Otherwise
+HTML5 inputs are used, which browsers show in the user's locale.
+
+Besides TimeWidget for points in time, DateWidget, TimeOfDayWidget,
+DateRangeWidget and DurationWidget fill the civil types Date,
+TimeOfDay and DateRange, which don't shift between timezones, and
+time.Duration values given like "1h 30m" or "90 min". Struct fields of
+these types get the widgets by default, limited by the "min" and "max"
+tag options:
+	type Booking struct {
+		Arrival htmlwidgets.Date      `htmlwidgets:"min=2026-01-01"`
+		Stay    htmlwidgets.DateRange `htmlwidgets:"required"`
+		Break   time.Duration         `htmlwidgets:"max=2h"`
+	}
+
+A FormSchema defines a form once and creates an independent Form for
+each request, so it may be shared by concurrent handlers:
+	var schema = htmlwidgets.NewFormSchemaFromStruct(App{})
+
+	func handle(w http.ResponseWriter, req *http.Request) {
+		data := App{}
+		form := schema.NewForm(&data)
+		...
+	}
 */
 package htmlwidgets
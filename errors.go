@@ -0,0 +1,107 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The kinds of FieldErrors.
+var (
+	// ErrUnknownField means that the data has no field for the id of a
+	// widget, e.g. because of a typo in the id or a missing struct
+	// field.
+	ErrUnknownField = errors.New("unknown field")
+	// ErrMissingKey means that a map has no entry or a slice has no
+	// item for the id of a widget.
+	ErrMissingKey = errors.New("missing key")
+	// ErrTypeMismatch means that the type of a field is not supported
+	// by its widget.
+	ErrTypeMismatch = errors.New("type mismatch")
+)
+
+// FieldError is the error reported by NewFormE, FillE, RenderDataE and
+// FillRequest if a widget doesn't match the form data.
+type FieldError struct {
+	// WidgetId is the id of the widget. It's empty if the form data
+	// itself is invalid.
+	WidgetId string
+	// Kind is ErrUnknownField, ErrMissingKey or ErrTypeMismatch.
+	Kind error
+	// Detail describes the error further.
+	Detail string
+}
+
+func (e *FieldError) Error() string {
+	msg := fmt.Sprintf("htmlwidgets: Field %q: %v", e.WidgetId, e.Kind)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// Unwrap returns the kind of the error, so errors.Is(err, ErrTypeMismatch)
+// tests the kind.
+func (e *FieldError) Unwrap() error {
+	return e.Kind
+}
+
+// fieldError returns a new FieldError with a formatted detail.
+func fieldError(id string, kind error, format string,
+	args ...interface{}) *FieldError {
+	return &FieldError{WidgetId: id, Kind: kind,
+		Detail: fmt.Sprintf(format, args...)}
+}
+
+// FieldErrors are the FieldErrors of multiple widgets.
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the FieldErrors, so errors.Is and errors.As inspect
+// each of them.
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// recoverFieldError calls fn and returns the FieldError it panics with.
+// Other panics are not recovered.
+func recoverFieldError(fn func()) (err *FieldError) {
+	defer func() {
+		if r := recover(); r != nil {
+			fieldErr, ok := r.(*FieldError)
+			if !ok {
+				panic(r)
+			}
+			err = fieldErr
+		}
+	}()
+	fn()
+	return nil
+}
@@ -0,0 +1,140 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type TestErrorsData struct {
+	Name  string
+	Age   int
+	Items []string
+}
+
+func TestNewFormE(t *testing.T) {
+	for i, data := range []interface{}{nil, 5, TestErrorsData{}, new(int)} {
+		form, err := NewFormE(data)
+		var fieldErr *FieldError
+		if form != nil || !errors.As(err, &fieldErr) ||
+			!errors.Is(err, ErrTypeMismatch) {
+			t.Errorf("Test %d: NewFormE returned %v, %v", i, form, err)
+		}
+	}
+	if _, err := NewFormE(&TestErrorsData{}); err != nil {
+		t.Errorf("NewFormE returned error %v", err)
+	}
+}
+
+// checkFieldErrors checks that err contains FieldErrors with the
+// given widget ids and kinds.
+func checkFieldErrors(t *testing.T, err error, expected map[string]error) {
+	var errs FieldErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected FieldErrors, got %#v", err)
+	}
+	if len(errs) != len(expected) {
+		t.Errorf("Errors are %v, expected kinds %v", err, expected)
+	}
+	for _, e := range errs {
+		if kind, ok := expected[e.WidgetId]; !ok || !errors.Is(e, kind) {
+			t.Errorf("Error %v doesn't match expected kinds %v", e, expected)
+		}
+	}
+	for _, kind := range expected {
+		if !errors.Is(err, kind) {
+			t.Errorf("Errors %v don't contain kind %v", err, kind)
+		}
+	}
+}
+
+func TestFormFillE(t *testing.T) {
+	data := TestErrorsData{}
+	form := NewForm(&data)
+	form.AddWidget(new(TextWidget), "Nmae", "", "")
+	form.AddWidget(new(TextWidget), "Age", "", "")
+	form.AddWidget(&ListWidget{InnerWidget: new(TextWidget)}, "Name", "", "")
+	form.AddWidget(new(TextWidget), "Items.0", "", "")
+	form.AddWidget(new(IntegerWidget), "Age.Years", "", "")
+	result, err := form.FillE(url.Values{
+		"Nmae":    []string{"Max"},
+		"Age":     []string{"Max"},
+		"Items.0": []string{"foo"},
+	})
+	checkFieldErrors(t, err, map[string]error{
+		"Nmae":      ErrUnknownField,
+		"Age":       ErrTypeMismatch,
+		"Name":      ErrTypeMismatch,
+		"Age.Years": ErrUnknownField,
+	})
+	if result.Valid {
+		t.Errorf("Result with FieldErrors should be invalid")
+	}
+	if !reflect.DeepEqual(data, TestErrorsData{Items: []string{"foo"}}) {
+		t.Errorf("Data is %v", data)
+	}
+
+	_, err = form.RenderDataE()
+	checkFieldErrors(t, err, map[string]error{
+		"Nmae":      ErrUnknownField,
+		"Name":      ErrTypeMismatch,
+		"Age.Years": ErrUnknownField,
+	})
+	func() {
+		defer func() {
+			if _, ok := recover().(*FieldError); !ok {
+				t.Errorf("RenderData should panic with a FieldError")
+			}
+		}()
+		form.RenderData()
+	}()
+}
+
+func TestFormFillEMap(t *testing.T) {
+	data := map[string]interface{}{"Items": []string{"a"}}
+	form := NewForm(data)
+	form.AddWidget(new(IntegerWidget), "Count", "", "")
+	form.AddWidget(new(TextWidget), "Items.3", "", "")
+	_, err := form.RenderDataE()
+	checkFieldErrors(t, err, map[string]error{
+		"Count":   ErrMissingKey,
+		"Items.3": ErrMissingKey,
+	})
+	_, err = form.FillE(url.Values{"Count": []string{"3"},
+		"Items.3": []string{"b"}})
+	checkFieldErrors(t, err, map[string]error{"Items.3": ErrMissingKey})
+	if data["Count"] != 3 {
+		t.Errorf("Count is %v", data["Count"])
+	}
+}
+
+func TestFormFillPanics(t *testing.T) {
+	data := TestErrorsData{}
+	form := NewForm(&data)
+	form.AddWidget(new(TextWidget), "Age", "", "")
+	defer func() {
+		err, ok := recover().(*FieldError)
+		if !ok || err.WidgetId != "Age" || err.Kind != ErrTypeMismatch {
+			t.Errorf("Fill panicked with %#v", err)
+		}
+	}()
+	form.Fill(url.Values{"Age": []string{"hello"}})
+	t.Errorf("Fill of mismatching field didn't panic")
+}
@@ -17,8 +17,8 @@
 package htmlwidgets
 
 import (
-	"fmt"
 	"html/template"
+	"mime/multipart"
 	"net/url"
 	"reflect"
 	"strconv"
@@ -34,16 +34,61 @@ type RenderData struct {
 	// element if the form may contain file input elements.
 	EncTypeAttr template.HTMLAttr
 	Action      string
+	// SubmitLabel is the translated label of the submit button.
+	SubmitLabel string
 }
 
+// FormError is an error reported by a FormValidator.
+type FormError struct {
+	// WidgetId is the id of the widget the error belongs to, which may
+	// be the full id of a child widget like "Address.City". Use an
+	// empty string for global form errors.
+	WidgetId string
+	Message  string
+}
+
+// FormValidator validates the filled data of a form as a whole, e.g. to
+// compare the values of multiple fields.
+//
+// It is called with the data given to NewForm and returns any errors
+// found.
+type FormValidator func(data interface{}) []FormError
+
 // Form represents an html form.
 type Form struct {
 	Widgets   []Widget
 	widgetMap map[string]Widget
 	data      interface{}
 	errors    map[string][]string
+	// validators are the form level validators.
+	validators []FormValidator
+	// validationErrors contains the errors of the form level validators.
+	validationErrors map[string][]string
+	// fieldErrors contains the errors of setting fields by the last
+	// fill.
+	fieldErrors FieldErrors
 	// Action defines the action parameter of the HTML form
 	Action string
+	// MaxMemory is the number of bytes of multipart requests kept in
+	// memory by FillRequest. If zero, DefaultMaxMemory is used.
+	MaxMemory int64
+	// MaxBodySize limits the size of request bodies read by FillRequest
+	// if greater than zero.
+	MaxBodySize int64
+	// Locale is the locale of the texts of the form like "de" or
+	// "en-US". If empty, DefaultLocale is used.
+	Locale string
+	// LocalizeInputs makes widgets parse and render numbers, dates and
+	// times in the LocaleFormat of the Locale as text inputs. If false,
+	// HTML5 inputs are used, which browsers show in the user's locale.
+	LocalizeInputs bool
+	// Translator translates labels, descriptions and messages. If nil,
+	// DefaultCatalog is used.
+	Translator Translator
+	// SubmitLabel is the label of the submit button, which is
+	// translated like the labels of widgets. If empty, the translation
+	// of "htmlwidgets.submit" is used.
+	SubmitLabel string
 }
 
 // WidgetById returns the widget with the given id.
@@ -73,43 +118,107 @@ func (f *Form) AddWidget(widget Widget, id, label, description string) Widget {
 //
 // In panics if data is not a pointer to a struct.
 func NewForm(data interface{}) *Form {
-	if dataType := reflect.TypeOf(data); (dataType.Kind() != reflect.Ptr ||
-		dataType.Elem().Kind() != reflect.Struct) &&
-		dataType.Kind() != reflect.Map {
+	form, err := NewFormE(data)
+	if err != nil {
 		panic("NewForm(data, widgets) expects data to" +
 			" be a map or a pointer to a struct.")
 	}
+	return form
+}
+
+// NewFormE creates a new Form like NewForm, but returns a FieldError
+// with kind ErrTypeMismatch if data is not a map or a pointer to a
+// struct.
+func NewFormE(data interface{}) (*Form, error) {
+	if dataType := reflect.TypeOf(data); dataType == nil ||
+		(dataType.Kind() != reflect.Ptr ||
+			dataType.Elem().Kind() != reflect.Struct) &&
+			dataType.Kind() != reflect.Map {
+		return nil, fieldError("", ErrTypeMismatch,
+			"expected a map or a pointer to a struct, got %T", data)
+	}
 	form := Form{
-		data:      data,
-		Widgets:   make([]Widget, 0),
-		widgetMap: make(map[string]Widget),
-		errors:    make(map[string][]string, 0)}
-	return &form
+		data:             data,
+		Widgets:          make([]Widget, 0),
+		widgetMap:        make(map[string]Widget),
+		errors:           make(map[string][]string, 0),
+		validationErrors: make(map[string][]string, 0)}
+	return &form, nil
 }
 
 // RenderData returns a RenderData struct for the form.
 //
 // It panics if a registered widget is not present in the data struct.
 func (f Form) RenderData() (renderData *RenderData) {
+	renderData, errs := f.renderData()
+	if len(errs) > 0 {
+		panic(errs[0])
+	}
+	return
+}
+
+// RenderDataE returns a RenderData struct for the form like RenderData.
+// Widgets not matching the form data are left out and reported as
+// FieldErrors.
+func (f Form) RenderDataE() (*RenderData, error) {
+	renderData, errs := f.renderData()
+	if len(errs) > 0 {
+		return renderData, errs
+	}
+	return renderData, nil
+}
+
+// renderData returns the render data of the form and the errors of the
+// widgets which could not be rendered.
+func (f Form) renderData() (renderData *RenderData, errs FieldErrors) {
 	renderData = new(RenderData)
 	renderData.Action = f.Action
+	submitLabel := f.SubmitLabel
+	if submitLabel == "" {
+		submitLabel = "htmlwidgets.submit"
+	}
+	renderData.SubmitLabel = f.translate(submitLabel, nil)
 	renderData.Widgets = make([]WidgetRenderData, 0)
 	for _, widget := range f.Widgets {
-		if _, ok := widget.(*FileWidget); ok {
+		if _, ok := widget.(MultipartWidget); ok {
 			renderData.EncTypeAttr = `enctype="multipart/form-data"`
 		}
-		widgetRenderData := widget.GetRenderData()
-		widgetRenderData.Errors = append(widgetRenderData.Errors,
-			f.errors[widget.Base().Id]...)
+		var widgetRenderData WidgetRenderData
+		if err := recoverFieldError(func() {
+			widgetRenderData = widget.GetRenderData()
+		}); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		widgetRenderData = f.prepareRenderData(widgetRenderData)
 		renderData.Widgets = append(renderData.Widgets, widgetRenderData)
 	}
-	renderData.Errors = f.errors[""]
+	renderData.Errors = append(renderData.Errors, f.errors[""]...)
+	renderData.Errors = append(renderData.Errors, f.validationErrors[""]...)
 	return
 }
 
+// prepareRenderData translates the given render data of a widget and
+// adds the errors added to the form for the widget. It's used for the
+// widgets of the form as well as for their children.
+func (f *Form) prepareRenderData(rd WidgetRenderData) WidgetRenderData {
+	rd = f.translateRenderData(rd)
+	rd.Errors = append(rd.Errors, f.errors[rd.Id]...)
+	rd.Errors = append(rd.Errors, f.validationErrors[rd.Id]...)
+	return rd
+}
+
+// AddValidator adds a form level validator. Form level validators are
+// run by Fill after all widgets have been filled.
+func (f *Form) AddValidator(validator FormValidator) {
+	f.validators = append(f.validators, validator)
+}
+
 // AddError adds an error to a widget's error list.
 //
 // To add global form errors, use an empty string as the widget's name.
+// Errors of child widgets of StructWidgets and items of ListWidgets are
+// added with their full ids like "Address.City" or "Tags.0".
 func (f *Form) AddError(widgetId string, error string) {
 	f.errors[widgetId] = append(f.errors[widgetId], error)
 }
@@ -119,11 +228,40 @@ func (f Form) getNestedField(field string) (reflect.Value, error) {
 	return f.findNestedField(field, nil, false)
 }
 
+// setField sets the field with the given id to value. Errors are
+// recorded and reported by FillE.
+func (f *Form) setField(id string, value interface{}) {
+	if _, err := f.findNestedField(id, value, false); err != nil {
+		f.fieldErrors = append(f.fieldErrors, err.(*FieldError))
+	}
+}
+
+// clearField sets the field with the given id to the zero value of its
+// type, i.e. nil for pointers. If the type can't be determined, the
+// zero value of defaultType is used.
+func (f *Form) clearField(id string, defaultType reflect.Type) {
+	t := defaultType
+	if field, err := f.getNestedField(id); err == nil && field.IsValid() {
+		t = field.Type()
+	}
+	f.setField(id, reflect.Zero(t).Interface())
+}
+
+// removeField removes the field with the given id from its parent
+// slice or map. Errors are recorded and reported by FillE.
+func (f *Form) removeField(id string) {
+	if _, err := f.findNestedField(id, nil, true); err != nil {
+		f.fieldErrors = append(f.fieldErrors, err.(*FieldError))
+	}
+}
+
 // findNestedField searches for the given field in the form data.
 //
 // If setValue is given, it will be set to the field.
 // If remove is given, the value will be removed from its parent slice
 // or map.
+//
+// Errors are of type *FieldError.
 func (f *Form) findNestedField(field string, setValue interface{}, remove bool) (
 	reflect.Value, error) {
 	parts := strings.Split(field, ".")
@@ -136,27 +274,53 @@ func (f *Form) findNestedField(field string, setValue interface{}, remove bool)
 		part := parts[0]
 		switch value.Type().Kind() {
 		case reflect.Ptr, reflect.Interface:
+			if value.IsNil() {
+				return reflect.Value{}, fieldError(field, ErrMissingKey,
+					"nil value before %q", part)
+			}
 			value = value.Elem()
 			continue
 		case reflect.Struct:
 			value = value.FieldByName(part)
+			if !value.IsValid() {
+				return reflect.Value{}, fieldError(field, ErrUnknownField,
+					"no struct field %q", part)
+			}
 		case reflect.Map:
+			if value.Type().Key().Kind() != reflect.String {
+				return reflect.Value{}, fieldError(field, ErrTypeMismatch,
+					"map keys are of type %v", value.Type().Key())
+			}
+			key := reflect.ValueOf(part).Convert(value.Type().Key())
 			if setIt {
-				value.SetMapIndex(reflect.ValueOf(part), reflect.ValueOf(setValue))
+				if !reflect.TypeOf(setValue).AssignableTo(value.Type().Elem()) {
+					return reflect.Value{}, fieldError(field, ErrTypeMismatch,
+						"can't set %T to map of %v", setValue, value.Type().Elem())
+				}
+				value.SetMapIndex(key, reflect.ValueOf(setValue))
 				return reflect.Value{}, nil
 			}
 			if removeIt {
-				value.SetMapIndex(reflect.ValueOf(part), reflect.Value{})
+				value.SetMapIndex(key, reflect.Value{})
 				return reflect.Value{}, nil
 			}
 			lastMapValue = value
-			lastMapIndex = reflect.ValueOf(part)
-			value = value.MapIndex(reflect.ValueOf(part))
+			lastMapIndex = key
+			value = value.MapIndex(key)
+			if !value.IsValid() {
+				return reflect.Value{}, fieldError(field, ErrMissingKey,
+					"no map entry %q", part)
+			}
 		case reflect.Slice:
 			index, err := strconv.Atoi(part)
 			if err != nil {
-				return reflect.Value{},
-					fmt.Errorf("Form: Expected index, got %q in field id %q", part, index)
+				return reflect.Value{}, fieldError(field, ErrUnknownField,
+					"expected index, got %q", part)
+			}
+			if index < 0 || index > value.Len() ||
+				removeIt && index == value.Len() {
+				return reflect.Value{}, fieldError(field, ErrMissingKey,
+					"index %d out of range", index)
 			}
 			if removeIt {
 				sliceSetvalue := reflect.AppendSlice(
@@ -181,18 +345,26 @@ func (f *Form) findNestedField(field string, setValue interface{}, remove bool)
 			}
 			value = value.Index(index)
 		default:
-			return reflect.Value{},
-				fmt.Errorf("form: Can't find field %q in data, reflect type: %v",
-					field, value.Type().Kind())
-		}
-		if !value.IsValid() {
-			return reflect.Value{},
-				fmt.Errorf("form: Invalid field %q in data", field)
+			return reflect.Value{}, fieldError(field, ErrUnknownField,
+				"can't find %q in %v", part, value.Type())
 		}
 		parts = parts[1:]
 	}
 	if setValue != nil {
-		if value.Type().Kind() == reflect.Ptr {
+		setType := reflect.TypeOf(setValue)
+		switch {
+		case !value.CanSet():
+			return reflect.Value{}, fieldError(field, ErrUnknownField,
+				"field can't be set")
+		case setType.AssignableTo(value.Type()):
+		case value.Type().Kind() == reflect.Ptr &&
+			setType.AssignableTo(value.Type().Elem()):
+		default:
+			return reflect.Value{}, fieldError(field, ErrTypeMismatch,
+				"can't set %v to field of type %v", setType, value.Type())
+		}
+		if value.Type().Kind() == reflect.Ptr &&
+			!setType.AssignableTo(value.Type()) {
 			v := reflect.New(value.Type().Elem())
 			v.Elem().Set(reflect.ValueOf(setValue))
 			value.Set(v)
@@ -206,21 +378,116 @@ func (f *Form) findNestedField(field string, setValue interface{}, remove bool)
 	return value, nil
 }
 
+// ActionWidget is implemented by widgets handling actions like adding
+// an item to a list, which are triggered by submitting parameters
+// prefixed with ActionPrefix.
+//
+// Forms call FillActions instead of Fill for these widgets.
+type ActionWidget interface {
+	Widget
+	// FillActions fills the widget like Fill and returns the actions
+	// triggered by the given values in addition.
+	FillActions(values url.Values) (valid bool, actions []FillAction)
+}
+
 // Fill fills the form data with the given values and validates the form.
 //
 // It panics if a widget has been set up which is not present in the
-// app data struct.
+// app data struct or whose field can't be set, like FillValues.
 //
 // Values that don't match a widget will be ignored.
 //
+// After all widgets have been filled, the form level validators are
+// run. Their errors replace those of the previous call to Fill.
+//
 // Returns true iff the form validates and there are none of the known
-// "htmlwidgets-action--*" parameters present.
+// "htmlwidgets-action--*" parameters present. Use FillValues to tell
+// them apart.
 func (f *Form) Fill(values url.Values) bool {
-	ret := true
+	result := f.FillValues(values)
+	return result.Valid && len(result.Actions) == 0
+}
+
+// FillValues fills the form like Fill and returns the detailed result.
+//
+// It panics with a FieldError if a widget doesn't match the form data.
+// This includes fields the filled value can't be set to, e.g. an int
+// field of a TextWidget, which earlier versions silently left
+// unchanged. Use FillE to get these errors returned instead.
+func (f *Form) FillValues(values url.Values) FillResult {
+	result, failed := f.fill(values, nil)
+	if len(failed) > 0 {
+		panic(failed[0])
+	}
+	if len(f.fieldErrors) > 0 {
+		panic(f.fieldErrors[0])
+	}
+	return result
+}
+
+// FillE fills the form like FillValues, but doesn't panic if widgets
+// don't match the form data. They are reported as FieldErrors and the
+// result is invalid then. The other widgets are filled as usual.
+func (f *Form) FillE(values url.Values) (FillResult, error) {
+	return f.fillE(values, nil)
+}
+
+// fillE fills the form like fill and returns the errors of the failed
+// widgets and of setting fields.
+func (f *Form) fillE(values url.Values,
+	files map[string][]*multipart.FileHeader) (FillResult, error) {
+	result, failed := f.fill(values, files)
+	errs := append(failed, f.fieldErrors...)
+	if len(errs) > 0 {
+		result.Valid = false
+		return result, errs
+	}
+	return result, nil
+}
+
+// fill fills the form with the given values and uploaded files.
+// MultipartWidgets are filled with the files, all other widgets with
+// the values only.
+//
+// It returns the errors of the widgets that panicked with a FieldError.
+// Errors of setting fields are stored in fieldErrors.
+func (f *Form) fill(values url.Values,
+	files map[string][]*multipart.FileHeader) (FillResult, FieldErrors) {
+	result := FillResult{Valid: true, Errors: make(map[string][]string)}
+	f.fieldErrors = nil
+	var failed FieldErrors
 	for _, widget := range f.Widgets {
-		if ok := widget.Fill(values); !ok {
-			ret = false
+		var ok bool
+		err := recoverFieldError(func() {
+			switch w := widget.(type) {
+			case MultipartWidget:
+				ok = w.FillMultipart(values, files)
+			case ActionWidget:
+				var actions []FillAction
+				ok, actions = w.FillActions(values)
+				result.Actions = append(result.Actions, actions...)
+			default:
+				ok = widget.Fill(values)
+			}
+		})
+		if err != nil {
+			failed = append(failed, err)
+		}
+		if !ok {
+			result.Valid = false
+		}
+		collectErrors(widget, result.Errors)
+	}
+	f.validationErrors = make(map[string][]string, 0)
+	for _, validator := range f.validators {
+		for _, err := range validator(f.data) {
+			message := f.translate(err.Message, nil)
+			f.validationErrors[err.WidgetId] = append(
+				f.validationErrors[err.WidgetId], message)
+			result.Errors[err.WidgetId] = append(result.Errors[err.WidgetId],
+				message)
+			result.Valid = false
 		}
 	}
-	return ret
+	return result, failed
 }
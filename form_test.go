@@ -280,3 +280,146 @@ func TestInitData(t *testing.T) {
 		t.Errorf("Filled data should be %v, is %v", expected, data)
 	}
 }
+
+type TestFormValidatorData struct {
+	Password, Confirmation string
+	Start, End             int
+}
+
+func TestFormValidator(t *testing.T) {
+	data := TestFormValidatorData{}
+	form := NewForm(&data)
+	form.AddWidget(new(PasswordWidget), "Password", "", "")
+	form.AddWidget(new(PasswordWidget), "Confirmation", "", "")
+	form.AddWidget(new(IntegerWidget), "Start", "", "")
+	form.AddWidget(new(IntegerWidget), "End", "", "")
+	form.AddValidator(func(data interface{}) []FormError {
+		d := data.(*TestFormValidatorData)
+		if d.Password != d.Confirmation {
+			return []FormError{{"Confirmation", "Passwords differ!"}}
+		}
+		return nil
+	})
+	form.AddValidator(func(data interface{}) []FormError {
+		d := data.(*TestFormValidatorData)
+		if d.End < d.Start {
+			return []FormError{{"End", "End before start!"},
+				{"", "Invalid range!"}}
+		}
+		return nil
+	})
+	form.AddError("", "Manual error")
+
+	vals := url.Values{
+		"Password":     []string{"foo"},
+		"Confirmation": []string{"bar"},
+		"Start":        []string{"2"},
+		"End":          []string{"1"},
+	}
+	if form.Fill(vals) {
+		t.Errorf("Fill returned true for invalid form")
+	}
+	renderData := form.RenderData()
+	if !reflect.DeepEqual(renderData.Errors, []string{"Manual error",
+		"Invalid range!"}) {
+		t.Errorf("Global errors are %v", renderData.Errors)
+	}
+	expected := [][]string{nil, []string{"Passwords differ!"}, nil,
+		[]string{"End before start!"}}
+	for i, errors := range expected {
+		if !reflect.DeepEqual(renderData.Widgets[i].Errors, errors) {
+			t.Errorf("Errors of widget %v are %v, expected %v", i,
+				renderData.Widgets[i].Errors, errors)
+		}
+	}
+
+	vals["Confirmation"] = []string{"foo"}
+	vals["End"] = []string{"3"}
+	if !form.Fill(vals) {
+		t.Errorf("Fill returned false for valid form")
+	}
+	renderData = form.RenderData()
+	if !reflect.DeepEqual(renderData.Errors, []string{"Manual error"}) {
+		t.Errorf("Global errors after valid fill are %v", renderData.Errors)
+	}
+	for i := range expected {
+		if len(renderData.Widgets[i].Errors) != 0 {
+			t.Errorf("Widget %v has errors after valid fill: %v", i,
+				renderData.Widgets[i].Errors)
+		}
+	}
+}
+
+func TestFormValidatorNested(t *testing.T) {
+	data := struct {
+		Address TestAddress
+		Tags    []string
+	}{}
+	form := NewForm(&data)
+	form.AddWidget(newTestAddressWidget(), "Address", "", "")
+	form.AddWidget(&ListWidget{InnerWidget: new(TextWidget)}, "Tags", "", "")
+	form.AddValidator(func(interface{}) []FormError {
+		return []FormError{{"Address.City", "Unknown city!"},
+			{"Tags.1", "Duplicate tag!"}}
+	})
+	form.AddError("Tags.0", "Manual error")
+	form.Fill(url.Values{
+		"Address.Street": []string{"Main St"},
+		"Address.City":   []string{"Nowhere"},
+		"Tags.0":         []string{"a"},
+		"Tags.1":         []string{"a"},
+	})
+	rd := form.RenderData()
+	fields := rd.Widgets[0].Data.(map[string]interface{})["Fields"].([]WidgetRenderData)
+	items := rd.Widgets[1].Data.(map[string]interface{})["Items"].([]ListItemRenderData)
+	if !reflect.DeepEqual(fields[1].Errors, []string{"Unknown city!"}) ||
+		!reflect.DeepEqual(items[0].Field.Errors, []string{"Manual error"}) ||
+		!reflect.DeepEqual(items[1].Field.Errors, []string{"Duplicate tag!"}) {
+		t.Errorf("Invalid errors of children %v and items %v", fields, items)
+	}
+}
+
+func TestFillValues(t *testing.T) {
+	data := struct {
+		Name string
+		Tags []string
+	}{}
+	form := NewForm(&data)
+	name := new(TextWidget)
+	name.Validators = []Validator{Required("Required!")}
+	form.AddWidget(name, "Name", "", "")
+	form.AddWidget(&ListWidget{InnerWidget: new(TextWidget)}, "Tags", "", "")
+	tests := []struct {
+		Values url.Values
+		Result FillResult
+	}{
+		{url.Values{"Name": []string{"Foo"}, "Tags.0": []string{"a"}},
+			FillResult{Valid: true, Errors: map[string][]string{}}},
+		{url.Values{"Tags.0": []string{"a"}},
+			FillResult{Errors: map[string][]string{"Name": []string{"Required!"}}}},
+		{url.Values{"Name": []string{"Foo"}, "Tags.0": []string{"a"},
+			"htmlwidgets-action--add-to-list": []string{"Tags"}},
+			FillResult{Valid: true, Actions: []FillAction{
+				{Name: "add-to-list", WidgetId: "Tags", Index: 1}},
+				Errors: map[string][]string{}}},
+		{url.Values{"Tags.0": []string{"a"}, "Tags.1": []string{"b"},
+			"htmlwidgets-action--remove-from-list": []string{"Tags.1"}},
+			FillResult{Actions: []FillAction{
+				{Name: "remove-from-list", WidgetId: "Tags", Index: 1}},
+				Errors: map[string][]string{"Name": []string{"Required!"}}}},
+	}
+	for i, test := range tests {
+		data.Tags = []string{"x", "y"}
+		result := form.FillValues(test.Values)
+		if !reflect.DeepEqual(result, test.Result) {
+			t.Errorf("Test %d: result is\n%#v\nexpected\n%#v", i, result,
+				test.Result)
+		}
+		data.Tags = []string{"x", "y"}
+		if form.Fill(test.Values) != (test.Result.Valid &&
+			len(test.Result.Actions) == 0) {
+			t.Errorf("Test %d: Fill should return false for invalid values"+
+				" or actions", i)
+		}
+	}
+}
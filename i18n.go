@@ -0,0 +1,293 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is the locale of forms without a Locale and the
+// fallback for messages missing in other locales.
+const DefaultLocale = "en"
+
+// Translator translates the texts of forms, i.e. labels, descriptions
+// and error messages.
+//
+// Keys are either the keys of the built-in messages listed in
+// DefaultCatalog or texts given by the app like labels and custom
+// error messages. Parameters like "{min}" in messages are replaced by
+// the given params. Translators should return the key with the params
+// replaced if they have no translation, so untranslated texts are
+// shown as given.
+type Translator interface {
+	Translate(locale, key string, params map[string]interface{}) string
+}
+
+// Message is an error with a message key and parameters. Validators
+// return Messages, so their messages are translated when added to the
+// errors of a widget.
+type Message struct {
+	Key    string
+	Params map[string]interface{}
+}
+
+// newMessage returns a new Message with the given key or custom if
+// it's not empty.
+func newMessage(custom, key string, params map[string]interface{}) *Message {
+	return &Message{defaultMessage(custom, key), params}
+}
+
+// Error returns the message in the DefaultLocale.
+func (m *Message) Error() string {
+	return DefaultCatalog.Translate(DefaultLocale, m.Key, m.Params)
+}
+
+// Catalog is a Translator with the messages of each locale keyed by
+// locale and message key. If a message is missing for a locale like
+// "de-AT", the language "de" and then DefaultLocale are tried.
+type Catalog map[string]map[string]string
+
+func (c Catalog) Translate(locale, key string,
+	params map[string]interface{}) string {
+	message, ok := c.lookup(locale, key)
+	if !ok {
+		message = key
+	}
+	return expandParams(message, params)
+}
+
+// lookup returns the message with the given key in the given locale or
+// its fallbacks.
+func (c Catalog) lookup(locale, key string) (string, bool) {
+	for _, l := range append(localeFallbacks(locale), DefaultLocale) {
+		if message, ok := c[l][key]; ok {
+			return message, true
+		}
+	}
+	return "", false
+}
+
+// expandParams replaces the parameters like "{min}" in message.
+func expandParams(message string, params map[string]interface{}) string {
+	if len(params) == 0 || !strings.Contains(message, "{") {
+		return message
+	}
+	pairs := make([]string, 0, 2*len(params))
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(message)
+}
+
+// DefaultCatalog contains the built-in messages in English and German.
+// It is used by forms without a Translator and for messages the
+// Translator of a form doesn't translate. Messages for other locales
+// may be added before forms are used.
+//
+// All messages of widgets get the parameter "label" with the label of
+// the widget.
+var DefaultCatalog = Catalog{
+	"en": {
+		"htmlwidgets.submit":             "Submit",
+		"htmlwidgets.required":           "This field is required.",
+		"htmlwidgets.min-length":         "Please enter at least {min} characters.",
+		"htmlwidgets.max-length":         "Please enter at most {max} characters.",
+		"htmlwidgets.format":             "Please enter a value in the requested format.",
+		"htmlwidgets.range":              "Please enter a number between {min} and {max}.",
+		"htmlwidgets.range-min":          "Please enter a number of at least {min}.",
+		"htmlwidgets.range-max":          "Please enter a number of at most {max}.",
+		"htmlwidgets.email":              "Please enter a valid email address.",
+		"htmlwidgets.url":                "Please enter a valid URL.",
+		"htmlwidgets.invalid-option":     "Please choose a valid option.",
+		"htmlwidgets.min-choices":        "Please choose at least {min} options.",
+		"htmlwidgets.max-choices":        "Please choose at most {max} options.",
+		"htmlwidgets.number":             "Please enter a valid number.",
+		"htmlwidgets.number-step":        "Please enter a multiple of {step}.",
+		"htmlwidgets.time":               "Please enter a valid date and time.",
+		"htmlwidgets.date":               "Please enter a valid date.",
+		"htmlwidgets.time-of-day":        "Please enter a valid time.",
+		"htmlwidgets.duration":           "Please enter a valid duration like 1h 30m.",
+		"htmlwidgets.min-date":           "Please enter a date not before {min}.",
+		"htmlwidgets.max-date":           "Please enter a date not after {max}.",
+		"htmlwidgets.min-time":           "Please enter a time not before {min}.",
+		"htmlwidgets.max-time":           "Please enter a time not after {max}.",
+		"htmlwidgets.min-duration":       "Please enter a duration of at least {min}.",
+		"htmlwidgets.max-duration":       "Please enter a duration of at most {max}.",
+		"htmlwidgets.date-order":         "The end date must not be before the start date.",
+		"htmlwidgets.passwords-mismatch": "The passwords do not match.",
+		"htmlwidgets.min-items":          "Please enter at least {min} items.",
+		"htmlwidgets.max-items":          "Please enter at most {max} items.",
+		"htmlwidgets.file-size":          "Please upload files of at most {max} bytes.",
+		"htmlwidgets.file-type":          "Please upload a file of an allowed type.",
+		"htmlwidgets.max-files":          "Please upload at most {max} files.",
+		"htmlwidgets.upload-read":        "The uploaded file could not be read.",
+		"htmlwidgets.upload-store":       "The uploaded file could not be stored.",
+	},
+	"de": {
+		"htmlwidgets.submit":             "Absenden",
+		"htmlwidgets.required":           "Dieses Feld ist erforderlich.",
+		"htmlwidgets.min-length":         "Bitte geben Sie mindestens {min} Zeichen ein.",
+		"htmlwidgets.max-length":         "Bitte geben Sie höchstens {max} Zeichen ein.",
+		"htmlwidgets.format":             "Bitte geben Sie einen Wert im geforderten Format ein.",
+		"htmlwidgets.range":              "Bitte geben Sie eine Zahl zwischen {min} und {max} ein.",
+		"htmlwidgets.range-min":          "Bitte geben Sie eine Zahl von mindestens {min} ein.",
+		"htmlwidgets.range-max":          "Bitte geben Sie eine Zahl von höchstens {max} ein.",
+		"htmlwidgets.email":              "Bitte geben Sie eine gültige E-Mail-Adresse ein.",
+		"htmlwidgets.url":                "Bitte geben Sie eine gültige URL ein.",
+		"htmlwidgets.invalid-option":     "Bitte wählen Sie eine gültige Option.",
+		"htmlwidgets.min-choices":        "Bitte wählen Sie mindestens {min} Optionen.",
+		"htmlwidgets.max-choices":        "Bitte wählen Sie höchstens {max} Optionen.",
+		"htmlwidgets.number":             "Bitte geben Sie eine gültige Zahl ein.",
+		"htmlwidgets.number-step":        "Bitte geben Sie ein Vielfaches von {step} ein.",
+		"htmlwidgets.time":               "Bitte geben Sie ein gültiges Datum mit Uhrzeit ein.",
+		"htmlwidgets.date":               "Bitte geben Sie ein gültiges Datum ein.",
+		"htmlwidgets.time-of-day":        "Bitte geben Sie eine gültige Uhrzeit ein.",
+		"htmlwidgets.duration":           "Bitte geben Sie eine gültige Dauer wie 1h 30m ein.",
+		"htmlwidgets.min-date":           "Bitte geben Sie ein Datum nicht vor dem {min} ein.",
+		"htmlwidgets.max-date":           "Bitte geben Sie ein Datum nicht nach dem {max} ein.",
+		"htmlwidgets.min-time":           "Bitte geben Sie eine Uhrzeit nicht vor {min} ein.",
+		"htmlwidgets.max-time":           "Bitte geben Sie eine Uhrzeit nicht nach {max} ein.",
+		"htmlwidgets.min-duration":       "Bitte geben Sie eine Dauer von mindestens {min} ein.",
+		"htmlwidgets.max-duration":       "Bitte geben Sie eine Dauer von höchstens {max} ein.",
+		"htmlwidgets.date-order":         "Das Enddatum darf nicht vor dem Startdatum liegen.",
+		"htmlwidgets.passwords-mismatch": "Die Passwörter stimmen nicht überein.",
+		"htmlwidgets.min-items":          "Bitte geben Sie mindestens {min} Einträge ein.",
+		"htmlwidgets.max-items":          "Bitte geben Sie höchstens {max} Einträge ein.",
+		"htmlwidgets.file-size":          "Bitte laden Sie Dateien mit höchstens {max} Bytes hoch.",
+		"htmlwidgets.file-type":          "Bitte laden Sie eine Datei eines erlaubten Typs hoch.",
+		"htmlwidgets.max-files":          "Bitte laden Sie höchstens {max} Dateien hoch.",
+		"htmlwidgets.upload-read":        "Die hochgeladene Datei konnte nicht gelesen werden.",
+		"htmlwidgets.upload-store":       "Die hochgeladene Datei konnte nicht gespeichert werden.",
+	},
+}
+
+// RequestLocale returns the one of the given locales best matching the
+// Accept-Language header of the request, e.g. to set Form.Locale.
+// Locales match language tags exactly or by their language, so "de"
+// matches "de-AT". The first locale is returned if none matches.
+func RequestLocale(req *http.Request, locales ...string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(req.Header.Get("Accept-Language"), ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+		if locale := matchLocale(tag, locales); locale != "" {
+			best, bestQ = locale, q
+		}
+	}
+	if best == "" && len(locales) > 0 {
+		return locales[0]
+	}
+	return best
+}
+
+// matchLocale returns the locale matching the given language tag
+// exactly or else by the language of the tag.
+func matchLocale(tag string, locales []string) string {
+	for _, locale := range locales {
+		if strings.EqualFold(locale, tag) {
+			return locale
+		}
+	}
+	if i := strings.IndexAny(tag, "-_"); i > 0 {
+		for _, locale := range locales {
+			if strings.EqualFold(locale, tag[:i]) {
+				return locale
+			}
+		}
+	}
+	return ""
+}
+
+// translate translates the given key in the locale of the form. Keys
+// not translated by the form's Translator are translated by
+// DefaultCatalog.
+func (f *Form) translate(key string, params map[string]interface{}) string {
+	locale := DefaultLocale
+	if f != nil && f.Locale != "" {
+		locale = f.Locale
+	}
+	if f != nil && f.Translator != nil {
+		if message := f.Translator.Translate(locale, key,
+			params); message != expandParams(key, params) {
+			return message
+		}
+	}
+	return DefaultCatalog.Translate(locale, key, params)
+}
+
+// translateRenderData translates the label and description of the
+// given render data.
+func (f *Form) translateRenderData(rd WidgetRenderData) WidgetRenderData {
+	rd.Label = f.translate(rd.Label, nil)
+	rd.Description = f.translate(rd.Description, nil)
+	return rd
+}
+
+// translateOptions returns a copy of the given options with translated
+// descriptions.
+func (f *Form) translateOptions(options []SelectOption) []SelectOption {
+	translated := make([]SelectOption, len(options))
+	for i, option := range options {
+		option.Description = f.translate(option.Description, nil)
+		translated[i] = option
+	}
+	return translated
+}
+
+// translate translates the given key in the locale of the widget's
+// form with the additional parameter "label".
+func (w *WidgetBase) translate(key string,
+	params map[string]interface{}) string {
+	withLabel := map[string]interface{}{"label": w.form.translate(w.Label, nil)}
+	for name, value := range params {
+		withLabel[name] = value
+	}
+	return w.form.translate(key, withLabel)
+}
+
+// addError adds the message with the given key to the widget's errors.
+// If custom is not empty, it's used as key instead.
+func (w *WidgetBase) addError(custom, key string,
+	params map[string]interface{}) {
+	w.Errors = append(w.Errors, w.translate(defaultMessage(custom, key),
+		params))
+}
+
+// errorMessage returns the translated message of the given error.
+// Messages are translated with their key and parameters, the texts of
+// other errors are used as keys.
+func (w *WidgetBase) errorMessage(err error) string {
+	if m, ok := err.(*Message); ok {
+		return w.translate(m.Key, m.Params)
+	}
+	return w.translate(err.Error(), nil)
+}
@@ -0,0 +1,174 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	catalog := Catalog{
+		"en": {"greeting": "Hello {name}!"},
+		"de": {"greeting": "Hallo {name}!", "bye": "Tschüss"},
+	}
+	params := map[string]interface{}{"name": "Max", "unused": 1}
+	tests := []struct{ Locale, Key, Expected string }{
+		{"en", "greeting", "Hello Max!"},
+		{"de", "greeting", "Hallo Max!"},
+		{"de-AT", "greeting", "Hallo Max!"},
+		{"fr", "greeting", "Hello Max!"},
+		{"en", "bye", "bye"},
+		{"de", "Unknown {name}", "Unknown Max"},
+	}
+	for i, test := range tests {
+		if msg := catalog.Translate(test.Locale, test.Key, params); msg !=
+			test.Expected {
+			t.Errorf("Test %d: Translated %q, expected %q", i, msg,
+				test.Expected)
+		}
+	}
+	if msg := MinLength(3, "").Validate("a").Error(); msg !=
+		"Please enter at least 3 characters." {
+		t.Errorf("Message is %q", msg)
+	}
+}
+
+func TestFormTranslation(t *testing.T) {
+	data := struct {
+		Name  string
+		Age   int
+		Email string
+		Tags  []string
+	}{}
+	form := NewForm(&data)
+	form.Locale = "de-DE"
+	form.Translator = Catalog{"de": {
+		"Age":                  "Alter",
+		"Your age":             "Ihr Alter",
+		"Add":                  "Hinzufügen",
+		"{label} is too short": "{label} ist zu kurz",
+		"Invalid form":         "Ungültiges Formular",
+	}}
+	name := form.AddWidget(new(TextWidget), "Name", "Name", "")
+	name.Base().Validators = []Validator{Required(""),
+		MinLength(3, "{label} is too short")}
+	form.AddWidget(&IntegerWidget{Min: "18"}, "Age", "Age",
+		"Your age")
+	email := form.AddWidget(new(TextWidget), "Email", "Email", "")
+	email.Base().Validators = []Validator{MinLength(3, "")}
+	form.AddWidget(&ListWidget{InnerWidget: new(TextWidget), AddLabel: "Add",
+		MaxItems: 1}, "Tags", "Tags", "")
+	form.AddValidator(func(interface{}) []FormError {
+		return []FormError{{"", "Invalid form"}}
+	})
+	result := form.FillValues(url.Values{
+		"Age":    []string{"12"},
+		"Email":  []string{"a"},
+		"Tags.0": []string{"a"},
+		"Tags.1": []string{"b"},
+	})
+	expected := map[string][]string{
+		"Name":  []string{"Dieses Feld ist erforderlich."},
+		"Age":   []string{"Bitte geben Sie eine Zahl von mindestens 18 ein."},
+		"Email": []string{"Bitte geben Sie mindestens 3 Zeichen ein."},
+		"Tags":  []string{"Bitte geben Sie höchstens 1 Einträge ein."},
+		"":      []string{"Ungültiges Formular"},
+	}
+	if !reflect.DeepEqual(result.Errors, expected) {
+		t.Errorf("Errors are %v, expected %v", result.Errors, expected)
+	}
+	form.FillValues(url.Values{"Name": []string{"Ma"}})
+	rd := form.RenderData()
+	if errors := rd.Widgets[0].Errors; !reflect.DeepEqual(errors,
+		[]string{"Name ist zu kurz"}) {
+		t.Errorf("Errors of name are %v", errors)
+	}
+	age := rd.Widgets[1]
+	if age.Label != "Alter" || age.Description != "Ihr Alter" {
+		t.Errorf("Label and description not translated: %#v", age)
+	}
+	if label := rd.Widgets[3].Data.(map[string]interface{})["AddLabel"]; label !=
+		"Hinzufügen" {
+		t.Errorf("AddLabel is %v", label)
+	}
+}
+
+func TestFormTranslationOptions(t *testing.T) {
+	data := struct{ Color, Shade string }{}
+	form := NewForm(&data)
+	form.Locale = "de"
+	form.Translator = Catalog{"de": {
+		"Red":  "Rot",
+		"Warm": "Warme Farben",
+		"Dark": "Dunkel",
+		"Save": "Speichern",
+	}}
+	options := []SelectOption{{Value: "red", Description: "Red"}}
+	form.AddWidget(&SelectWidget{Options: options,
+		Groups: map[string]string{"red": "Warm"}}, "Color", "", "")
+	form.AddWidget(&RadioWidget{Options: []SelectOption{{Value: "dark",
+		Description: "Dark"}}}, "Shade", "", "")
+	rd := form.RenderData()
+	color := rd.Widgets[0].Data.([]SelectOption)
+	shade := rd.Widgets[1].Data.([]SelectOption)
+	if color[0].Description != "Rot" || shade[0].Description != "Dunkel" {
+		t.Errorf("Options not translated: %v, %v", color, shade)
+	}
+	if groups := rd.Widgets[0].OptionGroups; len(groups) != 1 ||
+		groups[0].Label != "Warme Farben" ||
+		groups[0].Options[0].Description != "Rot" {
+		t.Errorf("Option groups not translated: %v", groups)
+	}
+	if options[0].Description != "Red" {
+		t.Errorf("Translation changed the options of the widget")
+	}
+	if rd.SubmitLabel != "Absenden" {
+		t.Errorf("Default submit label is %q", rd.SubmitLabel)
+	}
+	form.SubmitLabel = "Save"
+	var buf bytes.Buffer
+	if err := NewRenderer().Render(&buf, form.RenderData()); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `<button type="submit">Speichern</button>`) {
+		t.Errorf("Submit label not translated:\n%s", buf.String())
+	}
+}
+
+func TestRequestLocale(t *testing.T) {
+	tests := []struct{ Header, Expected string }{
+		{"", "en"},
+		{"de", "de"},
+		{"de-AT,de;q=0.9,en;q=0.8", "de"},
+		{"fr-FR, en-GB;q=0.8, de;q=0.9", "de"},
+		{"fr, en-US;q=0.5", "en"},
+		{"fr", "en"},
+	}
+	for i, test := range tests {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Language", test.Header)
+		if locale := RequestLocale(req, "en", "de"); locale != test.Expected {
+			t.Errorf("Test %d: Locale is %q, expected %q", i, locale,
+				test.Expected)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"strings"
+	"time"
+)
+
+// LocaleFormat describes how numbers, dates and times are written in a
+// locale.
+type LocaleFormat struct {
+	// DecimalSeparator and GroupSeparator separate the fraction and
+	// the groups of thousands of numbers like "1,234.5".
+	DecimalSeparator, GroupSeparator string
+	// DateLayouts, TimeLayouts and DateTimeLayouts are the layouts of
+	// dates, times of day and dates with times as used by the time
+	// package. Input is parsed with any of them, output is formatted
+	// with the first one.
+	DateLayouts, TimeLayouts, DateTimeLayouts []string
+}
+
+// LocaleFormats contains the formats of locales, keyed by locale. Forms
+// with LocalizeInputs and a Locale that has a format (directly or by
+// its language like "de" for "de-AT") parse and render numbers, dates
+// and times in this format instead of the formats of HTML5 inputs.
+// Formats for other locales may be added before forms are used.
+var LocaleFormats = map[string]LocaleFormat{
+	"en": {
+		DecimalSeparator: ".",
+		GroupSeparator:   ",",
+		DateLayouts:      []string{"01/02/2006", "1/2/2006"},
+		TimeLayouts:      []string{"3:04 PM", "3:04:05 PM", "15:04", "15:04:05"},
+		DateTimeLayouts: []string{"01/02/2006 3:04 PM", "1/2/2006 3:04 PM",
+			"1/2/2006 15:04"},
+	},
+	"de": {
+		DecimalSeparator: ",",
+		GroupSeparator:   ".",
+		DateLayouts:      []string{"02.01.2006", "2.1.2006"},
+		TimeLayouts:      []string{"15:04", "15:04:05"},
+		DateTimeLayouts: []string{"02.01.2006 15:04", "2.1.2006 15:04",
+			"2.1.2006 15:04:05"},
+	},
+}
+
+// localeFallbacks returns the given locale and its language if it has
+// a region like "de-AT".
+func localeFallbacks(locale string) []string {
+	locales := []string{locale}
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		locales = append(locales, locale[:i])
+	}
+	return locales
+}
+
+// localeFormat returns the format of the form's locale or nil if the
+// form doesn't localize inputs, has no locale or there is no format for
+// it.
+func (f *Form) localeFormat() *LocaleFormat {
+	if f == nil || !f.LocalizeInputs || f.Locale == "" {
+		return nil
+	}
+	for _, locale := range localeFallbacks(f.Locale) {
+		if format, ok := LocaleFormats[locale]; ok {
+			return &format
+		}
+	}
+	return nil
+}
+
+// parseNumber converts a number written in the locale to the format
+// expected by the package level parseNumber. Group separators are
+// optional, but have to separate groups of three digits.
+func (lf *LocaleFormat) parseNumber(s string) (string, bool) {
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+	integer, fraction := s, ""
+	if i := strings.Index(s, lf.DecimalSeparator); i >= 0 {
+		integer, fraction = s[:i], "."+s[i+len(lf.DecimalSeparator):]
+	}
+	if lf.GroupSeparator != "" && strings.Contains(integer, lf.GroupSeparator) {
+		groups := strings.Split(integer, lf.GroupSeparator)
+		for i, group := range groups {
+			if i == 0 && (len(group) == 0 || len(group) > 3) ||
+				i > 0 && len(group) != 3 {
+				return "", false
+			}
+		}
+		integer = strings.Join(groups, "")
+	}
+	number := sign + integer + fraction
+	return number, decimalRe.MatchString(number)
+}
+
+// formatNumber writes a number formatted by the package level
+// formatNumber in the locale.
+func (lf *LocaleFormat) formatNumber(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	integer, fraction := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		integer, fraction = s[:i], lf.DecimalSeparator+s[i+1:]
+	}
+	var groups []string
+	for len(integer) > 3 {
+		groups = append([]string{integer[len(integer)-3:]}, groups...)
+		integer = integer[:len(integer)-3]
+	}
+	groups = append([]string{integer}, groups...)
+	return sign + strings.Join(groups, lf.GroupSeparator) + fraction
+}
+
+// parseTime parses a time with the given layouts in the given location.
+func parseTime(s string, loc *time.Location, layouts ...string) (time.Time,
+	error) {
+	var err error
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -0,0 +1,189 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"bytes"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocaleFormatNumbers(t *testing.T) {
+	de := LocaleFormats["de"]
+	parseTests := []struct {
+		Input, Expected string
+		Valid           bool
+	}{
+		{"1.234,5", "1234.5", true},
+		{"1234,5", "1234.5", true},
+		{"-1.234.567", "-1234567", true},
+		{",5", ".5", true},
+		{"12", "12", true},
+		{"1.2,3", "", false},
+		{"1234.5", "", false},
+		{".123", "", false},
+		{"1,2,3", "", false},
+		{"abc", "", false},
+	}
+	for i, test := range parseTests {
+		number, ok := de.parseNumber(test.Input)
+		if ok != test.Valid || ok && number != test.Expected {
+			t.Errorf("Test %d: Parsed %q as %q, %v", i, test.Input, number, ok)
+		}
+	}
+	formatTests := []struct{ Input, Expected string }{
+		{"1234.5", "1.234,5"},
+		{"-1234567", "-1.234.567"},
+		{"123", "123"},
+		{"0.25", "0,25"},
+		{"", ""},
+	}
+	for i, test := range formatTests {
+		if s := de.formatNumber(test.Input); s != test.Expected {
+			t.Errorf("Test %d: Formatted %q as %q", i, test.Input, s)
+		}
+	}
+}
+
+func TestLocalizedWidgets(t *testing.T) {
+	data := struct {
+		Price float64
+		Count int
+		Start time.Time
+	}{}
+	form := NewForm(&data)
+	form.Locale = "de-AT"
+	form.LocalizeInputs = true
+	form.AddWidget(&NumberWidget{Min: "1000.5"}, "Price", "", "")
+	form.AddWidget(new(IntegerWidget), "Count", "", "")
+	form.AddWidget(new(TimeWidget), "Start", "", "")
+	if !form.Fill(url.Values{
+		"Price": []string{"1.234,5"},
+		"Count": []string{"12.345"},
+		"Start": []string{"16.10.2026 14:30"},
+	}) {
+		t.Fatalf("Fill failed: %v", form.RenderData())
+	}
+	start := time.Date(2026, time.October, 16, 14, 30, 0, 0, time.UTC)
+	if data.Price != 1234.5 || data.Count != 12345 || !data.Start.Equal(start) {
+		t.Errorf("Filled data is %v", data)
+	}
+	rd := form.RenderData()
+	if rd.Widgets[0].Data.(NumberRenderData).Value != "1.234,5" ||
+		rd.Widgets[1].Data != "12.345" ||
+		rd.Widgets[2].Data != "16.10.2026 14:30" {
+		t.Errorf("Invalid render data %v", rd.Widgets)
+	}
+	for i, widget := range rd.Widgets {
+		if !widget.Localized {
+			t.Errorf("Widget %d is not localized", i)
+		}
+	}
+	var buf bytes.Buffer
+	if err := NewRenderer().Render(&buf, rd); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	for _, expected := range []string{
+		`<input type="text" inputmode="decimal" id="Price" name="Price" value="1.234,5">`,
+		`<input type="text" id="Start" name="Start" value="16.10.2026 14:30">`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Rendered form lacks %s:\n%s", expected, buf.String())
+		}
+	}
+
+	result := form.FillValues(url.Values{
+		"Price": []string{"999,5"},
+		"Count": []string{"1.2"},
+		"Start": []string{"32.10.2026 14:30"},
+	})
+	expected := map[string][]string{
+		"Price": []string{"Bitte geben Sie eine Zahl von mindestens 1.000,5 ein."},
+		"Count": []string{"Bitte geben Sie eine gültige Zahl ein."},
+		"Start": []string{"Bitte geben Sie ein gültiges Datum mit Uhrzeit ein."},
+	}
+	if !reflect.DeepEqual(result.Errors, expected) {
+		t.Errorf("Errors are %v, expected %v", result.Errors, expected)
+	}
+	if data.Count != 12345 || !data.Start.Equal(start) {
+		t.Errorf("Invalid values changed data to %v", data)
+	}
+	rd = form.RenderData()
+	if rd.Widgets[1].Data != "1.2" || rd.Widgets[2].Data != "32.10.2026 14:30" {
+		t.Errorf("Invalid values not rendered again: %v", rd.Widgets)
+	}
+}
+
+func TestUnlocalizedWidgets(t *testing.T) {
+	data := struct {
+		Price float64
+		Start time.Time
+		Day   Date
+	}{}
+	form := NewForm(&data)
+	form.Locale = "en"
+	form.AddWidget(new(NumberWidget), "Price", "", "")
+	form.AddWidget(new(TimeWidget), "Start", "", "")
+	form.AddWidget(new(DateWidget), "Day", "", "")
+	if !form.Fill(url.Values{
+		"Price": []string{"1234.5"},
+		"Start": []string{"2026-10-16T14:30"},
+		"Day":   []string{"2026-10-16"},
+	}) {
+		t.Fatalf("Fill failed: %v", form.RenderData())
+	}
+	rd := form.RenderData()
+	for i, widget := range rd.Widgets {
+		if widget.Localized {
+			t.Errorf("Widget %d is localized without LocalizeInputs", i)
+		}
+	}
+	var buf bytes.Buffer
+	if err := NewRenderer().Render(&buf, rd); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	for _, expected := range []string{
+		`<input type="number" id="Price" name="Price" value="1234.5" step="any">`,
+		`<input type="datetime-local" id="Start" name="Start" value="2026-10-16T14:30">`,
+		`<input type="date" id="Day" name="Day" value="2026-10-16">`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Rendered form lacks %s:\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestTimeWidgetInvalid(t *testing.T) {
+	data := struct{ Start time.Time }{time.Now()}
+	form := NewForm(&data)
+	form.AddWidget(new(TimeWidget), "Start", "", "")
+	result := form.FillValues(url.Values{"Start": []string{"16.10.2026"}})
+	if result.Valid || !reflect.DeepEqual(result.Errors["Start"],
+		[]string{"Please enter a valid date and time."}) {
+		t.Errorf("Invalid result %v", result)
+	}
+	if data.Start.IsZero() {
+		t.Errorf("Unparseable value zeroed the time")
+	}
+	if rd := form.RenderData().Widgets[0]; rd.Localized ||
+		rd.Data != "16.10.2026" {
+		t.Errorf("Invalid render data %v", rd)
+	}
+}
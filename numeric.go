@@ -0,0 +1,325 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"fmt"
+	"math/big"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NumberRenderData is the Data of the render data of a NumberWidget.
+type NumberRenderData struct {
+	// Value is the formatted value or the submitted value if it could
+	// not be parsed.
+	Value string
+	// Min, Max and Step are the constraints of the widget and may be
+	// used as attributes of a HTML5 number input. Empty values mean no
+	// constraint, Step is "any" if the number may have a fraction.
+	Min, Max, Step string
+}
+
+// NumberWidget is a widget for numbers.
+//
+// The submitted value is parsed according to the type of the target
+// field, which may be any int, uint or float type or a string. Strings
+// hold decimal numbers of arbitrary size and precision like
+// "-12345678901234567890.123". If the type can't be determined (e.g.
+// for missing map entries), a float64 is filled in.
+//
+// If no value is submitted, the zero value is filled in and the
+// validators are run with nil, so use Required for mandatory numbers.
+type NumberWidget struct {
+	WidgetBase
+	// Min, Max and Step constrain the accepted values. They are decimal
+	// numbers, empty strings mean no constraint. Values have to be a
+	// multiple of Step away from Min (or zero if there is no Min).
+	Min, Max, Step string
+	// ParseError, MinError, MaxError and StepError are the messages
+	// for values that can't be parsed or violate the constraints. If
+	// empty, default messages are used.
+	ParseError, MinError, MaxError, StepError string
+	// invalid is the last submitted value if it could not be parsed.
+	invalid *string
+}
+
+var (
+	intType     = reflect.TypeOf(0)
+	float64Type = reflect.TypeOf(float64(0))
+	decimalRe   = regexp.MustCompile(`^[-+]?(\d+(\.\d*)?|\.\d+)$`)
+)
+
+func (w *NumberWidget) GetRenderData() WidgetRenderData {
+	value, err := w.form.getNestedField(w.Id)
+	if err != nil {
+		panic(err)
+	}
+	data := NumberRenderData{
+		Value: w.localNumber(formatNumber(value)),
+		Min:   w.Min,
+		Max:   w.Max,
+		Step:  w.Step,
+	}
+	if w.invalid != nil {
+		data.Value = *w.invalid
+	}
+	if data.Step == "" {
+		switch indirectType(value.Type()).Kind() {
+		case reflect.Float32, reflect.Float64, reflect.String:
+			data.Step = "any"
+		}
+	}
+	return WidgetRenderData{
+		WidgetBase: w.WidgetBase,
+		Template:   "number",
+		Data:       data,
+		Localized:  w.form.localeFormat() != nil}
+}
+
+// localNumber formats the given number for the locale of the form.
+func (w *NumberWidget) localNumber(s string) string {
+	if lf := w.form.localeFormat(); lf != nil {
+		return lf.formatNumber(s)
+	}
+	return s
+}
+
+func (w *NumberWidget) Fill(values url.Values) bool {
+	return w.fill(values, float64Type)
+}
+
+// fill fills the submitted number into the app struct. If the type of
+// the target field can't be determined, defaultType is used.
+func (w *NumberWidget) fill(values url.Values, defaultType reflect.Type) bool {
+	w.Errors = nil
+	w.invalid = nil
+	target := w.form.targetType(w.Id, defaultType)
+	raw := strings.TrimSpace(values.Get(w.Id))
+	if raw == "" {
+		w.form.clearField(w.Id, defaultType)
+		return w.validate(nil)
+	}
+	number, ok := raw, true
+	if lf := w.form.localeFormat(); lf != nil {
+		number, ok = lf.parseNumber(raw)
+	}
+	value, rat, err := parseNumber(number, target)
+	if !ok || err != nil {
+		w.invalid = &raw
+		w.addError(w.ParseError, "htmlwidgets.number", nil)
+		return false
+	}
+	w.form.setField(w.Id, value.Interface())
+	valid := w.checkConstraints(rat)
+	return w.validate(value.Interface()) && valid
+}
+
+func (w *NumberWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	if isNumberKind(indirectType(t).Kind()) {
+		return nil
+	}
+	return FieldErrors{fieldError(id, ErrTypeMismatch,
+		"NumberWidget can't fill numbers into %v", t)}
+}
+
+// checkConstraints checks the given number against Min, Max and Step
+// and adds errors for violated constraints.
+func (w *NumberWidget) checkConstraints(value *big.Rat) bool {
+	valid := true
+	min := parseConstraint("Min", w.Min)
+	max := parseConstraint("Max", w.Max)
+	step := parseConstraint("Step", w.Step)
+	if min != nil && value.Cmp(min) < 0 {
+		w.addError(w.MinError, "htmlwidgets.range-min",
+			map[string]interface{}{"min": w.localNumber(w.Min)})
+		valid = false
+	}
+	if max != nil && value.Cmp(max) > 0 {
+		w.addError(w.MaxError, "htmlwidgets.range-max",
+			map[string]interface{}{"max": w.localNumber(w.Max)})
+		valid = false
+	}
+	if step != nil && step.Sign() != 0 {
+		offset := new(big.Rat).Set(value)
+		if min != nil {
+			offset.Sub(offset, min)
+		}
+		if !offset.Quo(offset, step).IsInt() {
+			w.addError(w.StepError, "htmlwidgets.number-step",
+				map[string]interface{}{"step": w.localNumber(w.Step)})
+			valid = false
+		}
+	}
+	return valid
+}
+
+// parseConstraint parses the given constraint of a NumberWidget.
+//
+// It returns nil for empty constraints and panics for invalid ones.
+func parseConstraint(name, value string) *big.Rat {
+	if value == "" {
+		return nil
+	}
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		panic(fmt.Sprintf("htmlwidgets: Invalid %v %q of NumberWidget", name,
+			value))
+	}
+	return rat
+}
+
+// parseNumber parses the given string into a value of type t. It also
+// returns the exact value of the number.
+func parseNumber(s string, t reflect.Type) (reflect.Value, *big.Rat, error) {
+	value := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return value, nil, err
+		}
+		value.SetInt(v)
+		return value, new(big.Rat).SetInt64(v), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		v, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return value, nil, err
+		}
+		value.SetUint(v)
+		return value, new(big.Rat).SetInt(new(big.Int).SetUint64(v)), nil
+	case reflect.Float32, reflect.Float64:
+		if !decimalRe.MatchString(s) {
+			return value, nil, fmt.Errorf("invalid number %q", s)
+		}
+		v, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return value, nil, err
+		}
+		rat, _ := new(big.Rat).SetString(s)
+		value.SetFloat(v)
+		return value, rat, nil
+	case reflect.String:
+		if !decimalRe.MatchString(s) {
+			return value, nil, fmt.Errorf("invalid number %q", s)
+		}
+		rat, _ := new(big.Rat).SetString(s)
+		value.SetString(s)
+		return value, rat, nil
+	}
+	return value, nil, fmt.Errorf("can't parse numbers into %v", t)
+}
+
+// isNumberKind returns true if parseNumber supports types of the given
+// kind.
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	}
+	return false
+}
+
+// formatNumber formats the given number.
+func formatNumber(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return ""
+		}
+		return formatNumber(value.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, value.Type().Bits())
+	case reflect.String:
+		return value.String()
+	}
+	return ""
+}
+
+// IntegerWidget fills numbers like a NumberWidget, but defaults to int
+// if the type of the target field can't be determined. It uses the
+// "text" template and its render data contains the plain value.
+type IntegerWidget struct {
+	WidgetBase
+	// Min, Max, Step and the messages are the same as those of
+	// NumberWidget.
+	Min, Max, Step                            string
+	ParseError, MinError, MaxError, StepError string
+	// invalid is the last submitted value if it could not be parsed.
+	invalid *string
+}
+
+// number returns a NumberWidget with the settings of the widget. Use
+// update to take over the changes of the NumberWidget.
+func (w *IntegerWidget) number() *NumberWidget {
+	return &NumberWidget{
+		WidgetBase: w.WidgetBase,
+		Min:        w.Min,
+		Max:        w.Max,
+		Step:       w.Step,
+		ParseError: w.ParseError,
+		MinError:   w.MinError,
+		MaxError:   w.MaxError,
+		StepError:  w.StepError,
+		invalid:    w.invalid,
+	}
+}
+
+// update sets the errors and invalid value of the given NumberWidget
+// to the widget.
+func (w *IntegerWidget) update(number *NumberWidget) {
+	w.Errors = number.Errors
+	w.invalid = number.invalid
+}
+
+func (w *IntegerWidget) GetRenderData() WidgetRenderData {
+	rd := w.Base().GetRenderData()
+	rd.Template = "text"
+	if lf := w.form.localeFormat(); lf != nil {
+		if rd.Data != nil {
+			rd.Data = lf.formatNumber(formatNumber(reflect.ValueOf(rd.Data)))
+		}
+		rd.Localized = true
+	}
+	if w.invalid != nil {
+		rd.Data = *w.invalid
+	}
+	return rd
+}
+
+func (w *IntegerWidget) Fill(values url.Values) bool {
+	number := w.number()
+	valid := number.fill(values, intType)
+	w.update(number)
+	return valid
+}
+
+func (w *IntegerWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	return w.number().verifyField(id, t)
+}
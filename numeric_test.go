@@ -0,0 +1,228 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type TestIntegerWidgetData struct {
+	Id int
+}
+
+func TestIntegerWidget(t *testing.T) {
+	testWidget(t, &WidgetTest{
+		Widget:      new(IntegerWidget),
+		AppStruct:   &TestIntegerWidgetData{},
+		URLValue:    "42",
+		FilledValue: 42,
+		EmptyValue:  0,
+		RenderData:  42,
+		Template:    "text",
+	})
+	testWidget(t, &WidgetTest{
+		Widget:      new(IntegerWidget),
+		AppStruct:   &TestIntegerWidgetData{Id: 3},
+		URLValue:    "4x2",
+		FilledValue: 3,
+		EmptyValue:  0,
+		RenderData:  "4x2",
+		Error:       "Please enter a valid number.",
+		Template:    "text",
+	})
+	testWidget(t, &WidgetTest{
+		Widget: &IntegerWidget{WidgetBase: WidgetBase{Label: "Id"},
+			Max: "10", MaxError: "Too big!"},
+		AppStruct:   &TestIntegerWidgetData{},
+		URLValue:    "11",
+		FilledValue: 11,
+		EmptyValue:  0,
+		RenderData:  11,
+		Error:       "Too big!",
+		Template:    "text",
+	})
+}
+
+type TestNumberWidgetData struct {
+	Id int8
+}
+
+func TestNumberWidget(t *testing.T) {
+	testWidget(t, &WidgetTest{
+		Widget:      &NumberWidget{Min: "-10", Max: "100", Step: "2"},
+		AppStruct:   &TestNumberWidgetData{},
+		URLValue:    "-4",
+		FilledValue: int8(-4),
+		EmptyValue:  int8(0),
+		RenderData:  NumberRenderData{Value: "-4", Min: "-10", Max: "100", Step: "2"},
+		Template:    "number",
+	})
+	testWidget(t, &WidgetTest{
+		Widget:      &NumberWidget{ParseError: "Invalid!"},
+		AppStruct:   &TestNumberWidgetData{},
+		URLValue:    "128",
+		FilledValue: int8(0),
+		EmptyValue:  int8(0),
+		RenderData:  NumberRenderData{Value: "128"},
+		Error:       "Invalid!",
+		Template:    "number",
+	})
+}
+
+type TestNumberWidgetTypesData struct {
+	Int     int
+	Int16   int16
+	Int64   int64
+	Uint    uint
+	Uint8   uint8
+	Uint64  uint64
+	Float32 float32
+	Float64 float64
+	Decimal string
+	Pointer *int32
+}
+
+func TestNumberWidgetTypes(t *testing.T) {
+	tests := []struct {
+		Id, Value string
+		Expected  interface{}
+		Valid     bool
+	}{
+		{"Int", "-12", -12, true},
+		{"Int", "1.5", 0, false},
+		{"Int16", "32767", int16(32767), true},
+		{"Int16", "32768", int16(0), false},
+		{"Int64", "-9223372036854775808", int64(-9223372036854775808), true},
+		{"Uint", "12", uint(12), true},
+		{"Uint", "-1", uint(0), false},
+		{"Uint8", "255", uint8(255), true},
+		{"Uint64", "18446744073709551615", uint64(18446744073709551615), true},
+		{"Float32", "1.5", float32(1.5), true},
+		{"Float64", "-0.25", -0.25, true},
+		{"Float64", ".5", 0.5, true},
+		{"Float64", "1e3", 0.0, false},
+		{"Float64", "NaN", 0.0, false},
+		{"Decimal", "-123456789012345678901234567890.123", "-123456789012345678901234567890.123", true},
+		{"Decimal", "12a", "", false},
+		{"Pointer", "7", int32(7), true},
+	}
+	for i, test := range tests {
+		data := TestNumberWidgetTypesData{}
+		form := NewForm(&data)
+		form.AddWidget(new(NumberWidget), test.Id, "", "")
+		valid := form.Fill(url.Values{test.Id: []string{test.Value}})
+		if valid != test.Valid {
+			t.Errorf("Test %v: Fill returned %v, expected %v. Errors: %v", i,
+				valid, test.Valid, form.Widgets[0].Base().Errors)
+		}
+		value := reflect.ValueOf(data).FieldByName(test.Id)
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				t.Errorf("Test %v: Pointer has not been set", i)
+				continue
+			}
+			value = value.Elem()
+		}
+		if !reflect.DeepEqual(value.Interface(), test.Expected) {
+			t.Errorf("Test %v: Filled value is %#v, expected %#v", i,
+				value.Interface(), test.Expected)
+		}
+	}
+}
+
+func TestNumberWidgetConstraints(t *testing.T) {
+	tests := []struct {
+		Widget *NumberWidget
+		Value  string
+		Errors []string
+	}{
+		{&NumberWidget{Min: "1", Max: "10"}, "1", nil},
+		{&NumberWidget{Min: "1", Max: "10"}, "10", nil},
+		{&NumberWidget{Min: "1", Max: "10"}, "0.9",
+			[]string{"Please enter a number of at least 1."}},
+		{&NumberWidget{Min: "1", Max: "10", MaxError: "Too big!"}, "10.1",
+			[]string{"Too big!"}},
+		{&NumberWidget{Step: "0.1"}, "0.3", nil},
+		{&NumberWidget{Step: "0.1"}, "0.35",
+			[]string{"Please enter a multiple of 0.1."}},
+		{&NumberWidget{Min: "0.5", Step: "2"}, "4.5", nil},
+		{&NumberWidget{Min: "0.5", Step: "2", StepError: "Step!"}, "4",
+			[]string{"Step!"}},
+		{&NumberWidget{Min: "5", Step: "2"}, "2",
+			[]string{"Please enter a number of at least 5.",
+				"Please enter a multiple of 2."}},
+	}
+	for i, test := range tests {
+		data := TestNumberWidgetTypesData{}
+		form := NewForm(&data)
+		form.AddWidget(test.Widget, "Float64", "", "")
+		valid := form.Fill(url.Values{"Float64": []string{test.Value}})
+		if valid != (test.Errors == nil) ||
+			!reflect.DeepEqual(test.Widget.Errors, test.Errors) {
+			t.Errorf("Test %v: Fill returned %v with errors %v, expected %v", i,
+				valid, test.Widget.Errors, test.Errors)
+		}
+	}
+}
+
+func TestNumberWidgetDecimalRange(t *testing.T) {
+	data := TestNumberWidgetTypesData{}
+	form := NewForm(&data)
+	widget := new(NumberWidget)
+	widget.Validators = []Validator{Range(0, 100, "Out of range!")}
+	form.AddWidget(widget, "Decimal", "", "")
+	if !form.Fill(url.Values{"Decimal": []string{"99.99"}}) ||
+		data.Decimal != "99.99" {
+		t.Errorf("Fill failed with errors %v, data %q", widget.Errors,
+			data.Decimal)
+	}
+	if form.Fill(url.Values{"Decimal": []string{"100.01"}}) ||
+		!reflect.DeepEqual(widget.Errors, []string{"Out of range!"}) {
+		t.Errorf("Fill accepted a decimal out of range, errors %v",
+			widget.Errors)
+	}
+}
+
+func TestNumberWidgetMissingValue(t *testing.T) {
+	data := TestNumberWidgetTypesData{Int: 5}
+	form := NewForm(&data)
+	widget := new(IntegerWidget)
+	form.AddWidget(widget, "Int", "", "")
+	if !form.Fill(url.Values{}) {
+		t.Errorf("Fill without value returned false")
+	}
+	if data.Int != 0 {
+		t.Errorf("Filled value is %v, expected 0", data.Int)
+	}
+	widget.Validators = []Validator{Required("Required!")}
+	if form.Fill(url.Values{}) {
+		t.Errorf("Fill without required value returned true")
+	}
+	if !form.Fill(url.Values{"Int": []string{"0"}}) {
+		t.Errorf("Fill with required zero value returned false")
+	}
+
+	value := 1.5
+	pointer := struct{ Value *float64 }{&value}
+	form = NewForm(&pointer)
+	form.AddWidget(new(NumberWidget), "Value", "", "")
+	if !form.Fill(url.Values{}) || pointer.Value != nil {
+		t.Errorf("Fill without value set pointer to %v", pointer.Value)
+	}
+}
@@ -0,0 +1,220 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// FormTemplate is the id of the template used to render a whole form.
+const FormTemplate = "form"
+
+// DefaultTemplates contains the templates used by a new Renderer, keyed
+// by the template id returned in WidgetRenderData.Template.
+//
+// Widget templates are executed with the WidgetRenderData of the
+// widget, the form template with the RenderData of the form. All
+// templates may use the functions "widget" (renders a
+// WidgetRenderData), "classes" (renders the class attribute of a
+// widget), "optionGroups" (see GroupOptions) and "submitLabel"
+// (returns Renderer.SubmitLabel) as well as the template "options"
+// (renders the options of a choice widget's WidgetRenderData as
+// <option> elements).
+var DefaultTemplates = map[string]string{
+	FormTemplate: `<form action="{{.Action}}" method="POST" accept-charset="utf-8"{{with .EncTypeAttr}} {{.}}{{end}}>
+{{- with .Errors}}<ul class="errors">{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{- range .Widgets}}
+{{- if eq .Template "hidden"}}{{widget .}}{{else}}
+<div class="field{{if .Errors}} error{{end}}">
+{{- if .Label}}<label for="{{.Id}}">{{.Label}}</label>{{end}}
+{{- widget .}}
+{{- with .Description}}<span class="help">{{.}}</span>{{end}}
+{{- with .Errors}}<ul class="errors">{{range .}}<li>{{.}}</li>{{end}}</ul>{{end -}}
+</div>{{end}}
+{{- end}}
+<button type="submit">{{or submitLabel .SubmitLabel}}</button>
+</form>`,
+	"text": `<input type="text" id="{{.Id}}" name="{{.Id}}" value="{{.Data}}"{{classes .}}>`,
+	"password": `<input type="password" id="{{.Id}}" name="{{.Id}}"{{classes .}}>
+{{- if .Data.Verify}}
+<label for="{{.Data.VerifyId}}">{{.Data.VerifyLabel}}</label><input type="password" id="{{.Data.VerifyId}}" name="{{.Data.VerifyId}}"{{classes .}}>
+{{- with .Data.VerifyDescription}}<span class="help">{{.}}</span>{{end}}
+{{- end}}`,
+	"number": `{{if .Localized}}<input type="text" inputmode="decimal" id="{{.Id}}" name="{{.Id}}" value="{{.Data.Value}}"{{classes .}}>
+{{- else}}<input type="number" id="{{.Id}}" name="{{.Id}}" value="{{.Data.Value}}"
+{{- with .Data.Min}} min="{{.}}"{{end}}{{with .Data.Max}} max="{{.}}"{{end}}{{with .Data.Step}} step="{{.}}"{{end}}{{classes .}}>{{end}}`,
+	"textarea":    `<textarea id="{{.Id}}" name="{{.Id}}"{{classes .}}>{{.Data}}</textarea>`,
+	"checkbox":    `<input type="checkbox" id="{{.Id}}" name="{{.Id}}" value="true"{{if .Data}} checked{{end}}{{classes .}}>`,
+	"select":      `<select id="{{.Id}}" name="{{.Id}}"{{classes .}}>{{template "options" .}}</select>`,
+	"multiselect": `<select multiple id="{{.Id}}" name="{{.Id}}"{{classes .}}>{{template "options" .}}</select>`,
+	"checkboxes": `<div id="{{.Id}}" class="checkboxes{{range .Classes}} {{.}}{{end}}">
+{{- $id := .Id}}
+{{- range optionGroups .}}
+{{- if .Label}}<fieldset><legend>{{.Label}}</legend>{{end}}
+{{- range .Options}}<label><input type="checkbox" name="{{$id}}" value="{{.Value}}"{{if .Selected}} checked{{end}}{{if .Disabled}} disabled{{end}}> {{.Description}}</label>{{end}}
+{{- if .Label}}</fieldset>{{end}}
+{{- end -}}
+</div>`,
+	"radio": `<div id="{{.Id}}" class="radio{{range .Classes}} {{.}}{{end}}">
+{{- $id := .Id}}
+{{- range optionGroups .}}
+{{- if .Label}}<fieldset><legend>{{.Label}}</legend>{{end}}
+{{- range .Options}}<label><input type="radio" name="{{$id}}" value="{{.Value}}"{{if .Selected}} checked{{end}}{{if .Disabled}} disabled{{end}}> {{.Description}}</label>{{end}}
+{{- if .Label}}</fieldset>{{end}}
+{{- end -}}
+</div>`,
+	"hidden": `<input type="hidden" id="{{.Id}}" name="{{.Id}}" value="{{.Data}}">`,
+	"file": `<input type="file" id="{{.Id}}" name="{{.Id}}"{{classes .}}>
+{{- with .Data.Token}}<input type="hidden" name="{{$.Id}}--token" value="{{.}}">{{end}}
+{{- with .Data.Filenames}}<span class="uploaded">{{range $i, $name := .}}{{if $i}}, {{end}}{{$name}}{{end}}</span>{{end}}`,
+	"time": `<input type="{{if .Localized}}text{{else}}datetime-local{{end}}" id="{{.Id}}" name="{{.Id}}" value="{{.Data}}"{{classes .}}>`,
+	"date": `<input type="{{if .Localized}}text{{else}}date{{end}}" id="{{.Id}}" name="{{.Id}}" value="{{.Data.Value}}"
+{{- if not .Localized}}{{with .Data.Min}} min="{{.}}"{{end}}{{with .Data.Max}} max="{{.}}"{{end}}{{end}}{{classes .}}>`,
+	"timeofday": `<input type="{{if .Localized}}text{{else}}time{{end}}" id="{{.Id}}" name="{{.Id}}" value="{{.Data.Value}}"
+{{- if not .Localized}}{{with .Data.Min}} min="{{.}}"{{end}}{{with .Data.Max}} max="{{.}}"{{end}}{{end}}{{classes .}}>`,
+	"daterange": `<div id="{{.Id}}" class="daterange{{range .Classes}} {{.}}{{end}}">
+{{- if .Localized}}<input type="text" name="{{.Id}}.Start" value="{{.Data.Start}}"> &ndash; <input type="text" name="{{.Id}}.End" value="{{.Data.End}}">
+{{- else}}<input type="date" name="{{.Id}}.Start" value="{{.Data.Start}}"{{with .Data.Min}} min="{{.}}"{{end}}{{with .Data.Max}} max="{{.}}"{{end}}> &ndash; <input type="date" name="{{.Id}}.End" value="{{.Data.End}}"{{with .Data.Min}} min="{{.}}"{{end}}{{with .Data.Max}} max="{{.}}"{{end}}>
+{{- end -}}
+</div>`,
+	"duration": `<input type="text" id="{{.Id}}" name="{{.Id}}" value="{{.Data.Value}}"{{classes .}}>`,
+	"struct": `<fieldset id="{{.Id}}" class="struct{{range .Classes}} {{.}}{{end}}">
+{{- range .Data.Fields}}
+{{- if eq .Template "hidden"}}{{widget .}}{{else}}
+<div class="field{{if .Errors}} error{{end}}">
+{{- if .Label}}<label for="{{.Id}}">{{.Label}}</label>{{end}}
+{{- widget .}}
+{{- with .Description}}<span class="help">{{.}}</span>{{end}}
+{{- with .Errors}}<ul class="errors">{{range .}}<li>{{.}}</li>{{end}}</ul>{{end -}}
+</div>{{end}}
+{{- end}}
+</fieldset>`,
+	"list": `<div id="{{.Id}}" class="list{{range .Classes}} {{.}}{{end}}">
+{{- $data := .Data}}
+{{- range .Data.Items}}
+<div class="list-item field{{if .Field.Errors}} error{{end}}">
+{{- if .Field.Label}}<label for="{{.Field.Id}}">{{.Field.Label}}</label>{{end}}
+{{- widget .Field}}
+{{- if and $data.InsertLabel $data.CanAdd}}<button type="submit" name="htmlwidgets-action--insert-before" value="{{.Field.Id}}">{{$data.InsertLabel}}</button>{{end}}
+{{- if and $data.MoveUpLabel (not .First)}}<button type="submit" name="htmlwidgets-action--move-up" value="{{.Field.Id}}">{{$data.MoveUpLabel}}</button>{{end}}
+{{- if and $data.MoveDownLabel (not .Last)}}<button type="submit" name="htmlwidgets-action--move-down" value="{{.Field.Id}}">{{$data.MoveDownLabel}}</button>{{end}}
+{{- if $data.CanRemove}}<button type="submit" name="htmlwidgets-action--remove-from-list" value="{{.Field.Id}}">{{$data.RemoveLabel}}</button>{{end}}
+{{- with .Field.Description}}<span class="help">{{.}}</span>{{end}}
+{{- with .Field.Errors}}<ul class="errors">{{range .}}<li>{{.}}</li>{{end}}</ul>{{end -}}
+</div>
+{{- end}}
+{{- if .Data.CanAdd}}
+<button type="submit" name="htmlwidgets-action--add-to-list" value="{{.Id}}">{{$data.AddLabel}}</button>
+{{- end}}
+</div>`,
+}
+
+// optionsTemplate renders the options of select elements. It is
+// available as template "options" in all templates.
+const optionsTemplate = `{{define "options"}}
+{{- range optionGroups .}}
+{{- if .Label}}<optgroup label="{{.Label}}">{{end}}
+{{- range .Options}}<option value="{{.Value}}"{{if .Selected}} selected{{end}}{{if .Disabled}} disabled{{end}}>{{.Description}}</option>{{end}}
+{{- if .Label}}</optgroup>{{end}}
+{{- end}}
+{{- end}}`
+
+// Renderer renders forms and widgets using html/template.
+//
+// A new Renderer uses the DefaultTemplates which may be replaced by
+// calling SetTemplate.
+type Renderer struct {
+	// SubmitLabel replaces the submit labels of all rendered forms if
+	// set. By default, the SubmitLabel of the RenderData is used.
+	SubmitLabel string
+	templates   map[string]*template.Template
+}
+
+// NewRenderer creates a new Renderer using the default templates.
+func NewRenderer() *Renderer {
+	r := &Renderer{
+		templates: make(map[string]*template.Template),
+	}
+	for id, text := range DefaultTemplates {
+		if err := r.SetTemplate(id, text); err != nil {
+			panic(fmt.Sprintf("htmlwidgets: Invalid default template %q: %v",
+				id, err))
+		}
+	}
+	return r
+}
+
+// SetTemplate parses the given template text and uses it for the
+// given template id, replacing any previous template.
+func (r *Renderer) SetTemplate(id, text string) error {
+	tmpl, err := template.New(id).Funcs(template.FuncMap{
+		"widget":       r.widget,
+		"classes":      classesAttr,
+		"submitLabel":  func() string { return r.SubmitLabel },
+		"optionGroups": GroupOptions,
+	}).Parse(optionsTemplate)
+	if err == nil {
+		tmpl, err = tmpl.Parse(text)
+	}
+	if err != nil {
+		return err
+	}
+	r.templates[id] = tmpl
+	return nil
+}
+
+// RenderWidget writes the HTML of the given widget to out.
+func (r *Renderer) RenderWidget(out io.Writer, data WidgetRenderData) error {
+	tmpl, ok := r.templates[data.Template]
+	if !ok {
+		return fmt.Errorf("htmlwidgets: No template %q for widget %q",
+			data.Template, data.Id)
+	}
+	return tmpl.Execute(out, data)
+}
+
+// Render writes the HTML of the given form to out.
+func (r *Renderer) Render(out io.Writer, data *RenderData) error {
+	tmpl, ok := r.templates[FormTemplate]
+	if !ok {
+		return fmt.Errorf("htmlwidgets: No template %q", FormTemplate)
+	}
+	return tmpl.Execute(out, data)
+}
+
+// widget renders the given widget for use inside of templates.
+func (r *Renderer) widget(data WidgetRenderData) (template.HTML, error) {
+	var buf bytes.Buffer
+	if err := r.RenderWidget(&buf, data); err != nil {
+		return "", err
+	}
+	return template.HTML(buf.String()), nil
+}
+
+// classesAttr returns the class attribute for the given widget or an
+// empty string if it has no classes.
+func classesAttr(data WidgetRenderData) template.HTMLAttr {
+	if len(data.Classes) == 0 {
+		return ""
+	}
+	return template.HTMLAttr(fmt.Sprintf(` class="%v"`,
+		template.HTMLEscapeString(strings.Join(data.Classes, " "))))
+}
@@ -0,0 +1,231 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type TestRendererData struct {
+	Name     string
+	Password string
+	Bio      string
+	Alive    bool
+	Color    string
+	Token    string
+	Avatar   string
+	Born     time.Time
+	Tags     []string
+	Weight   float64
+}
+
+func TestRendererRender(t *testing.T) {
+	data := TestRendererData{
+		Name:   "Foo <Bar>",
+		Bio:    "Hello",
+		Alive:  true,
+		Token:  "secret",
+		Born:   time.Date(1985, time.April, 10, 8, 10, 0, 0, time.UTC),
+		Tags:   []string{"a", "b"},
+		Weight: 2.5,
+	}
+	form := NewForm(&data)
+	form.Action = "/save"
+	name := form.AddWidget(new(TextWidget), "Name", "Name", "Your name")
+	name.Base().Classes = []string{"wide", "big"}
+	form.AddWidget(&PasswordWidget{VerifyLabel: "Repeat"}, "Password",
+		"Password", "")
+	form.AddWidget(new(TextAreaWidget), "Bio", "Bio", "")
+	form.AddWidget(new(BoolWidget), "Alive", "Alive", "")
+	form.AddWidget(&SelectWidget{Options: []SelectOption{
+		SelectOption{"red", "Red", false},
+		SelectOption{"blue", "Blue", true},
+	}}, "Color", "Color", "")
+	form.AddWidget(new(HiddenWidget), "Token", "", "")
+	form.AddWidget(new(FileWidget), "Avatar", "Avatar", "")
+	form.AddWidget(new(TimeWidget), "Born", "Born", "")
+	form.AddWidget(&NumberWidget{Min: "0.5"}, "Weight", "Weight", "")
+	form.AddWidget(&ListWidget{InnerWidget: new(TextWidget),
+		AddLabel: "Add", RemoveLabel: "Remove", MoveDownLabel: "Down"},
+		"Tags", "Tags", "")
+	form.AddError("", "Global error")
+	form.AddError("Name", "Name error")
+
+	var buf bytes.Buffer
+	if err := NewRenderer().Render(&buf, form.RenderData()); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	out := buf.String()
+	for _, expected := range []string{
+		`<form action="/save" method="POST" accept-charset="utf-8" enctype="multipart/form-data">`,
+		`<li>Global error</li>`,
+		`<div class="field error"><label for="Name">Name</label>`,
+		`<input type="text" id="Name" name="Name" value="Foo &lt;Bar&gt;" class="wide big">`,
+		`<span class="help">Your name</span>`,
+		`<li>Name error</li>`,
+		`<input type="password" id="Password" name="Password">`,
+		`<label for="Password--verify">Repeat</label><input type="password" id="Password--verify" name="Password--verify">`,
+		`<textarea id="Bio" name="Bio">Hello</textarea>`,
+		`<input type="checkbox" id="Alive" name="Alive" value="true" checked>`,
+		`<option value="red">Red</option><option value="blue" selected>Blue</option>`,
+		`<input type="hidden" id="Token" name="Token" value="secret">`,
+		`<input type="file" id="Avatar" name="Avatar">`,
+		`<input type="datetime-local" id="Born" name="Born" value="1985-04-10T08:10">`,
+		`<input type="number" id="Weight" name="Weight" value="2.5" min="0.5" step="any">`,
+		`<input type="text" id="Tags.1" name="Tags.1" value="b">`,
+		`<button type="submit" name="htmlwidgets-action--move-down" value="Tags.0">Down</button><button type="submit" name="htmlwidgets-action--remove-from-list" value="Tags.0">Remove</button>`,
+		`<button type="submit" name="htmlwidgets-action--add-to-list" value="Tags">Add</button>`,
+		`<button type="submit">Submit</button>`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Rendered form does not contain\n%v\nOutput:\n%v", expected, out)
+		}
+	}
+	if strings.Contains(out, `value="Tags.1">Down</button>`) {
+		t.Errorf("Last list item should be rendered without move down button:\n%v", out)
+	}
+	if strings.Contains(out, `<label for="Token">`) {
+		t.Errorf("Hidden widget should be rendered without label:\n%v", out)
+	}
+}
+
+func TestRenderListItems(t *testing.T) {
+	data := struct{ Tags []string }{}
+	form := NewForm(&data)
+	inner := new(TextWidget)
+	inner.Label = "Tag"
+	inner.Validators = []Validator{MinLength(2, "Too short!")}
+	form.AddWidget(&ListWidget{InnerWidget: inner}, "Tags", "Tags", "")
+	form.Fill(url.Values{"Tags.0": []string{"ab"}, "Tags.1": []string{"c"}})
+	var buf bytes.Buffer
+	if err := NewRenderer().Render(&buf, form.RenderData()); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	out := buf.String()
+	for _, expected := range []string{
+		`<div class="list-item field"><label for="Tags.0">Tag</label><input type="text" id="Tags.0" name="Tags.0" value="ab">`,
+		`<div class="list-item field error"><label for="Tags.1">Tag</label>`,
+		`<ul class="errors"><li>Too short!</li></ul></div>`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Rendered form does not contain\n%v\nOutput:\n%v", expected, out)
+		}
+	}
+}
+
+func TestRendererSetTemplate(t *testing.T) {
+	data := TestRendererData{Name: "Foo"}
+	form := NewForm(&data)
+	form.AddWidget(new(TextWidget), "Name", "Name", "")
+	renderer := NewRenderer()
+	if err := renderer.SetTemplate("text", `<b>{{.Data}}</b>`); err != nil {
+		t.Fatalf("SetTemplate failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := renderer.RenderWidget(&buf, form.RenderData().Widgets[0]); err != nil {
+		t.Fatalf("RenderWidget failed: %v", err)
+	}
+	if buf.String() != "<b>Foo</b>" {
+		t.Errorf("Rendered widget is %q, should be %q", buf.String(), "<b>Foo</b>")
+	}
+	if err := renderer.SetTemplate("text", `{{.Data`); err == nil {
+		t.Errorf("SetTemplate should fail for invalid templates")
+	}
+	buf.Reset()
+	err := renderer.RenderWidget(&buf, WidgetRenderData{Template: "unknown"})
+	if err == nil {
+		t.Errorf("RenderWidget should fail for unknown templates")
+	}
+}
+
+func TestRendererChoiceTemplates(t *testing.T) {
+	options := []SelectOption{
+		SelectOption{"foo", "Foo", true},
+		SelectOption{"bar", "Bar", false},
+	}
+	tests := []struct {
+		Template string
+		Expected string
+	}{
+		{"multiselect", `<select multiple id="Id" name="Id"><option value="foo" selected>Foo</option><option value="bar">Bar</option></select>`},
+		{"radio", `<div id="Id" class="radio"><label><input type="radio" name="Id" value="foo" checked> Foo</label><label><input type="radio" name="Id" value="bar"> Bar</label></div>`},
+		{"checkboxes", `<div id="Id" class="checkboxes"><label><input type="checkbox" name="Id" value="foo" checked> Foo</label><label><input type="checkbox" name="Id" value="bar"> Bar</label></div>`},
+	}
+	renderer := NewRenderer()
+	for _, test := range tests {
+		var buf bytes.Buffer
+		err := renderer.RenderWidget(&buf, WidgetRenderData{
+			WidgetBase: WidgetBase{Id: "Id"},
+			Template:   test.Template,
+			Data:       options,
+		})
+		if err != nil {
+			t.Errorf("Rendering %q failed: %v", test.Template, err)
+		}
+		if buf.String() != test.Expected {
+			t.Errorf("Template %q rendered\n%v\nexpected\n%v", test.Template,
+				buf.String(), test.Expected)
+		}
+	}
+}
+
+func TestRendererOptionGroups(t *testing.T) {
+	var buf bytes.Buffer
+	err := NewRenderer().RenderWidget(&buf, WidgetRenderData{
+		WidgetBase: WidgetBase{Id: "Id"},
+		Template:   "select",
+		Data: []SelectOption{
+			SelectOption{"a", "A", false},
+			SelectOption{"b", "B", true},
+			SelectOption{"c", "C", false},
+		},
+		OptionGroups: []SelectOptionGroup{
+			{"", []GroupedOption{{SelectOption{"a", "A", false}, false}}},
+			{"X", []GroupedOption{{SelectOption{"b", "B", true}, false},
+				{SelectOption{"c", "C", false}, true}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Rendering failed: %v", err)
+	}
+	expected := `<select id="Id" name="Id"><option value="a">A</option><optgroup label="X"><option value="b" selected>B</option><option value="c" disabled>C</option></optgroup></select>`
+	if buf.String() != expected {
+		t.Errorf("Rendered\n%v\nexpected\n%v", buf.String(), expected)
+	}
+}
+
+func TestRendererStructTemplate(t *testing.T) {
+	data := struct{ Address TestAddress }{TestAddress{"Main St", "Berlin"}}
+	form := NewForm(&data)
+	form.AddWidget(newTestAddressWidget(), "Address", "Address", "")
+	var buf bytes.Buffer
+	if err := NewRenderer().Render(&buf, form.RenderData()); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `<label for="Address">Address</label><fieldset id="Address" class="struct">
+<div class="field"><label for="Address.Street">Street</label><input type="text" id="Address.Street" name="Address.Street" value="Main St"></div>
+<div class="field"><label for="Address.City">City</label><input type="text" id="Address.City" name="Address.City" value="Berlin"><span class="help">Your city</span></div>
+</fieldset>`
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("Rendered form does not contain\n%v\nOutput:\n%v", expected,
+			buf.String())
+	}
+}
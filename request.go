@@ -0,0 +1,162 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxMemory is the number of bytes of a multipart request that
+// FillRequest keeps in memory if Form.MaxMemory is not set. Larger
+// files are stored in temporary files.
+const DefaultMaxMemory = 32 << 20
+
+// ActionPrefix is the prefix of the names of submitted parameters
+// triggering actions like adding an item to a list.
+const ActionPrefix = "htmlwidgets-action--"
+
+// FillResult is the result of filling a form.
+type FillResult struct {
+	// Valid is true if all widgets and form level validators accept
+	// the submitted values.
+	Valid bool
+	// Actions are the actions triggered by the submitted values, e.g.
+	// adding an item to a list. The form should be rendered again if
+	// there are any, even if it's valid.
+	Actions []FillAction
+	// Errors are the errors found, keyed by widget id. Errors of the
+	// children of StructWidgets and the items of ListWidgets have their
+	// full ids like "Address.City" or "Tags.0". Global errors have an
+	// empty id.
+	Errors map[string][]string
+}
+
+// FillAction is an action triggered by a submitted parameter prefixed
+// with ActionPrefix.
+type FillAction struct {
+	// Name is the name of the action without ActionPrefix, e.g.
+	// "add-to-list".
+	Name string
+	// WidgetId is the id of the widget handling the action.
+	WidgetId string
+	// Index is the index of the list item the action refers to.
+	Index int
+}
+
+// FillRequest fills the form with the values and files of the given
+// request and validates the form like FillValues.
+//
+// The values of GET and HEAD requests are taken from the URL query,
+// those of other requests from the body only. Multipart bodies are
+// parsed keeping Form.MaxMemory bytes in memory. Files not kept in
+// memory are stored in temporary files that remain accessible through
+// bound *multipart.FileHeader values until the caller removes them with
+// req.MultipartForm.RemoveAll.
+//
+// Values are expected to be encoded in UTF-8 or ISO-8859-1, as given
+// by the charset of the content type or the "_charset_" parameter.
+//
+// An error is returned if the request can't be read, e.g. because the
+// body is larger than Form.MaxBodySize or the charset is not
+// supported. The form is not filled in this case. Widgets not matching
+// the form data are reported as FieldErrors like by FillE.
+func (f *Form) FillRequest(req *http.Request) (FillResult, error) {
+	values, files, err := f.requestValues(req)
+	if err != nil {
+		return FillResult{}, err
+	}
+	return f.fillE(values, files)
+}
+
+// requestValues returns the submitted values and files of the given
+// request.
+func (f *Form) requestValues(req *http.Request) (url.Values,
+	map[string][]*multipart.FileHeader, error) {
+	if req.Method == "GET" || req.Method == "HEAD" {
+		values := req.URL.Query()
+		values, err := decodeCharset(values, values.Get("_charset_"))
+		return values, nil, err
+	}
+	if f.MaxBodySize > 0 && req.Body != nil {
+		req.Body = http.MaxBytesReader(nil, req.Body, f.MaxBodySize)
+	}
+	contentType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	var files map[string][]*multipart.FileHeader
+	if contentType == "multipart/form-data" {
+		maxMemory := f.MaxMemory
+		if maxMemory == 0 {
+			maxMemory = DefaultMaxMemory
+		}
+		if err := req.ParseMultipartForm(maxMemory); err != nil {
+			return nil, nil, fmt.Errorf("htmlwidgets: Invalid request: %v", err)
+		}
+		files = req.MultipartForm.File
+	} else if err := req.ParseForm(); err != nil {
+		return nil, nil, fmt.Errorf("htmlwidgets: Invalid request: %v", err)
+	}
+	charset := params["charset"]
+	if charset == "" {
+		charset = req.PostForm.Get("_charset_")
+	}
+	values, err := decodeCharset(req.PostForm, charset)
+	return values, files, err
+}
+
+// decodeCharset returns the given values converted from the given
+// charset to UTF-8.
+//
+// It returns an error for unsupported charsets and invalid UTF-8.
+func decodeCharset(values url.Values, charset string) (url.Values, error) {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "utf8", "us-ascii":
+		for key, vs := range values {
+			valid := utf8.ValidString(key)
+			for _, v := range vs {
+				valid = valid && utf8.ValidString(v)
+			}
+			if !valid {
+				return nil, fmt.Errorf(
+					"htmlwidgets: Invalid UTF-8 in parameter %q", key)
+			}
+		}
+		return values, nil
+	case "iso-8859-1", "latin1", "latin-1":
+		decoded := make(url.Values, len(values))
+		for key, vs := range values {
+			for _, v := range vs {
+				decoded.Add(latin1ToUTF8(key), latin1ToUTF8(v))
+			}
+		}
+		return decoded, nil
+	}
+	return nil, fmt.Errorf("htmlwidgets: Unsupported charset %q", charset)
+}
+
+// latin1ToUTF8 converts the given ISO-8859-1 encoded string to UTF-8.
+func latin1ToUTF8(s string) string {
+	runes := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		runes[i] = rune(s[i])
+	}
+	return string(runes)
+}
@@ -0,0 +1,130 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type TestFillRequestData struct {
+	Name string
+	Tags []string
+}
+
+// newTestRequestForm returns a form with a required Name and a list of
+// Tags.
+func newTestRequestForm(data *TestFillRequestData) *Form {
+	form := NewForm(data)
+	name := new(TextWidget)
+	name.Validators = []Validator{Required("")}
+	form.AddWidget(name, "Name", "", "")
+	form.AddWidget(&ListWidget{InnerWidget: new(TextWidget)}, "Tags", "", "")
+	return form
+}
+
+func TestFormFillRequest(t *testing.T) {
+	tests := []struct {
+		Method, URL, ContentType, Body string
+		Name                           string
+		Result                         FillResult
+		Error                          bool
+	}{
+		{"GET", "/?Name=Foo", "", "", "Foo",
+			FillResult{Valid: true, Errors: map[string][]string{}}, false},
+		{"POST", "/?Name=Query", "application/x-www-form-urlencoded",
+			"Name=Body", "Body",
+			FillResult{Valid: true, Errors: map[string][]string{}}, false},
+		{"POST", "/?Name=Query", "application/x-www-form-urlencoded", "", "",
+			FillResult{Errors: map[string][]string{
+				"Name": []string{"This field is required."}}}, false},
+		{"POST", "/", "application/x-www-form-urlencoded",
+			"Name=Foo&Tags.0=a&htmlwidgets-action--add-to-list=Tags", "Foo",
+			FillResult{Valid: true, Actions: []FillAction{
+				{Name: "add-to-list", WidgetId: "Tags", Index: 1}},
+				Errors: map[string][]string{}}, false},
+		{"POST", "/", "application/x-www-form-urlencoded; charset=ISO-8859-1",
+			"Name=M%FCller", "Müller",
+			FillResult{Valid: true, Errors: map[string][]string{}}, false},
+		{"POST", "/", "application/x-www-form-urlencoded",
+			"_charset_=latin1&Name=M%FCller", "Müller",
+			FillResult{Valid: true, Errors: map[string][]string{}}, false},
+		{"POST", "/", "application/x-www-form-urlencoded", "Name=M%FCller",
+			"", FillResult{}, true},
+		{"POST", "/", "application/x-www-form-urlencoded; charset=koi8-r",
+			"Name=Foo", "", FillResult{}, true},
+		{"POST", "/", "application/x-www-form-urlencoded",
+			"Name=" + strings.Repeat("x", 100), "", FillResult{}, true},
+	}
+	for i, test := range tests {
+		data := TestFillRequestData{}
+		form := newTestRequestForm(&data)
+		form.MaxBodySize = 100
+		req, _ := http.NewRequest(test.Method, test.URL,
+			strings.NewReader(test.Body))
+		if test.ContentType != "" {
+			req.Header.Set("Content-Type", test.ContentType)
+		}
+		result, err := form.FillRequest(req)
+		if (err != nil) != test.Error {
+			t.Errorf("Test %d: error is %v, expected error: %v", i, err,
+				test.Error)
+			continue
+		}
+		if !reflect.DeepEqual(result, test.Result) {
+			t.Errorf("Test %d: result is %#v, expected %#v", i, result,
+				test.Result)
+		}
+		if data.Name != test.Name {
+			t.Errorf("Test %d: name is %q, expected %q", i, data.Name, test.Name)
+		}
+	}
+}
+
+func TestFormFillRequestMaxMemory(t *testing.T) {
+	data := struct{ Id []Upload }{}
+	form := NewForm(&data)
+	form.AddWidget(new(FileWidget), "Id", "", "")
+	form.MaxMemory = 1
+	req := newUploadRequest(t, nil, testUploadFile{"Id", "b.txt", testText})
+	result, err := form.FillRequest(req)
+	if err != nil || !result.Valid {
+		t.Fatalf("FillRequest failed: %v %v", result, err)
+	}
+	defer req.MultipartForm.RemoveAll()
+	if len(data.Id) != 1 || string(data.Id[0].Data) != string(testText) {
+		t.Errorf("Upload stored on disk should be read, got %#v", data.Id)
+	}
+}
+
+func TestDecodeCharsetKeepsValues(t *testing.T) {
+	tags := make([]string, 1, 2)
+	tags[0] = "a"
+	values := map[string][]string{"Tags": tags}
+	if _, err := decodeCharset(values, "utf-8"); err != nil {
+		t.Fatalf("decodeCharset failed: %v", err)
+	}
+	if extra := tags[:2][1]; extra != "" {
+		t.Errorf("decodeCharset wrote %q into the submitted values", extra)
+	}
+	values["\xff"] = []string{"b"}
+	if _, err := decodeCharset(values, "utf-8"); err == nil {
+		t.Errorf("decodeCharset accepted an invalid key")
+	}
+}
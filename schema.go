@@ -0,0 +1,140 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"reflect"
+	"sync"
+)
+
+// FormSchema is the definition of a form, i.e. its widgets with their
+// labels and validators and the form level validators. It's built once,
+// e.g. at startup, and creates a Form bound to the data of each request
+// with NewForm.
+//
+// A FormSchema is safe for concurrent use by multiple goroutines. It
+// becomes immutable when the first form has been created: AddWidget and
+// AddValidator panic afterwards, and the exported fields and the added
+// widgets must not be changed anymore.
+type FormSchema struct {
+	// Action, MaxMemory, MaxBodySize, Translator and SubmitLabel are
+	// copied to the created forms. Set the Locale of each form for the
+	// request.
+	Action      string
+	MaxMemory   int64
+	MaxBodySize int64
+	Translator  Translator
+	SubmitLabel string
+	mutex       sync.Mutex
+	frozen      bool
+	widgets     []Widget
+	validators  []FormValidator
+}
+
+// NewFormSchema creates a new, empty FormSchema.
+func NewFormSchema() *FormSchema {
+	return new(FormSchema)
+}
+
+// NewFormSchemaFromStruct creates a new FormSchema with widgets derived
+// from the struct tags of the given struct like NewFormFromStruct. data
+// may be a struct or a pointer to a struct and is only used for its
+// type.
+func NewFormSchemaFromStruct(data interface{}) *FormSchema {
+	dataType := reflect.TypeOf(data)
+	if dataType != nil && dataType.Kind() == reflect.Ptr {
+		dataType = dataType.Elem()
+	}
+	if dataType == nil || dataType.Kind() != reflect.Struct {
+		panic("NewFormSchemaFromStruct(data) expects data to be a struct.")
+	}
+	schema := NewFormSchema()
+	err := addStructWidgets(schema, dataType, "", make(map[reflect.Type]bool))
+	if err != nil {
+		panic("htmlwidgets: " + err.Error())
+	}
+	return schema
+}
+
+// checkMutable panics if the schema is immutable already.
+func (s *FormSchema) checkMutable() {
+	if s.frozen {
+		panic("htmlwidgets: FormSchema can't be changed after creating forms")
+	}
+}
+
+// AddWidget adds a new widget to the schema and sets the given
+// attributes.
+//
+// It returns the added widget
+func (s *FormSchema) AddWidget(widget Widget, id, label,
+	description string) Widget {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.checkMutable()
+	base := widget.Base()
+	base.Id = id
+	base.Label = label
+	base.Description = description
+	s.widgets = append(s.widgets, widget)
+	return widget
+}
+
+// AddValidator adds a form level validator.
+func (s *FormSchema) AddValidator(validator FormValidator) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.checkMutable()
+	s.validators = append(s.validators, validator)
+}
+
+// NewForm creates a new Form bound to the given data like the package
+// level NewForm. The form gets copies of the schema's widgets, so it
+// may be filled and rendered independently of other forms.
+//
+// In panics if data is not a pointer to a struct or a map.
+func (s *FormSchema) NewForm(data interface{}) *Form {
+	s.mutex.Lock()
+	s.frozen = true
+	s.mutex.Unlock()
+	form := NewForm(data)
+	form.Action = s.Action
+	form.MaxMemory = s.MaxMemory
+	form.MaxBodySize = s.MaxBodySize
+	form.Translator = s.Translator
+	form.SubmitLabel = s.SubmitLabel
+	for _, widget := range s.widgets {
+		base := widget.Base()
+		form.AddWidget(cloneWidget(widget), base.Id, base.Label,
+			base.Description)
+	}
+	form.validators = append([]FormValidator(nil), s.validators...)
+	return form
+}
+
+// WidgetById returns the widget of the schema with the given id or nil
+// if there is none.
+func (s *FormSchema) WidgetById(id string) Widget {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, widget := range s.widgets {
+		if widget.Base().Id == id {
+			return widget
+		}
+	}
+	return nil
+}
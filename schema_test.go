@@ -0,0 +1,101 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type TestSchemaData struct {
+	Name string   `htmlwidgets:"required,error=Name required!"`
+	Age  int      `htmlwidgets:"min=0"`
+	Tags []string `htmlwidgets:"maxitems=2"`
+}
+
+func TestFormSchema(t *testing.T) {
+	schema := NewFormSchemaFromStruct(TestSchemaData{})
+	schema.Action = "/save"
+	schema.AddValidator(func(data interface{}) []FormError {
+		if data.(*TestSchemaData).Age == 13 {
+			return []FormError{{"", "Unlucky!"}}
+		}
+		return nil
+	})
+	var wg sync.WaitGroup
+	errors := make(chan string, 100)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data := TestSchemaData{}
+			form := schema.NewForm(&data)
+			values := url.Values{
+				"Age":    []string{fmt.Sprint(i)},
+				"Tags.0": []string{fmt.Sprint(i)},
+			}
+			if i%2 == 0 {
+				values.Set("Name", fmt.Sprintf("Name %d", i))
+			}
+			result := form.FillValues(values)
+			expected := TestSchemaData{Age: i, Tags: []string{fmt.Sprint(i)}}
+			expectedErrors := map[string][]string{}
+			if i%2 != 0 {
+				expectedErrors["Name"] = []string{"Name required!"}
+			}
+			if i == 13 {
+				expectedErrors[""] = []string{"Unlucky!"}
+			}
+			if i%2 == 0 {
+				expected.Name = fmt.Sprintf("Name %d", i)
+			}
+			if !reflect.DeepEqual(data, expected) {
+				errors <- fmt.Sprintf("Form %d: data is %v, expected %v", i, data,
+					expected)
+			}
+			if !reflect.DeepEqual(result.Errors, expectedErrors) {
+				errors <- fmt.Sprintf("Form %d: errors are %v, expected %v", i,
+					result.Errors, expectedErrors)
+			}
+			rd := form.RenderData()
+			if rd.Action != "/save" || !reflect.DeepEqual(rd.Widgets[0].Errors,
+				expectedErrors["Name"]) {
+				errors <- fmt.Sprintf("Form %d: invalid render data %v", i, rd)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errors)
+	for err := range errors {
+		t.Error(err)
+	}
+	if errors := schema.WidgetById("Name").Base().Errors; errors != nil {
+		t.Errorf("Schema widget has errors %v", errors)
+	}
+	if schema.WidgetById("Unknown") != nil {
+		t.Errorf("WidgetById should return nil for unknown ids")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("AddWidget should panic after creating forms")
+		}
+	}()
+	schema.AddWidget(new(TextWidget), "Other", "", "")
+}
@@ -0,0 +1,279 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultUploadMaxAge is the time uploads are kept by the UploadStores
+// of this package if their MaxAge is not set.
+const DefaultUploadMaxAge = time.Hour
+
+// ErrUploadNotFound is returned by UploadStore.Get for unknown or
+// expired tokens.
+var ErrUploadNotFound = errors.New("htmlwidgets: Upload not found")
+
+// UploadStore stashes uploaded files between requests, so they don't
+// have to be uploaded again if a form is rendered again, e.g. because
+// of validation errors.
+//
+// Uploads expire after a maximum age given by the implementation. Get
+// doesn't return expired uploads, but they are only removed by Cleanup,
+// which should be called periodically by the app, e.g. from a
+// time.Ticker.
+//
+// Implementations must be safe for concurrent use.
+type UploadStore interface {
+	// Put stores the given upload and returns a token identifying it.
+	// Tokens are sent to the client and must not be guessable.
+	Put(upload Upload) (token string, err error)
+	// Get returns the upload with the given token or ErrUploadNotFound
+	// if it's unknown or has expired.
+	Get(token string) (Upload, error)
+	// Delete removes the upload with the given token.
+	Delete(token string) error
+	// Cleanup removes all expired uploads.
+	Cleanup() error
+}
+
+// tokenRe matches the tokens returned by newUploadToken.
+var tokenRe = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// uploadMaxAge returns the given maximum age or DefaultUploadMaxAge if
+// it's zero.
+func uploadMaxAge(maxAge time.Duration) time.Duration {
+	if maxAge == 0 {
+		return DefaultUploadMaxAge
+	}
+	return maxAge
+}
+
+// newUploadToken returns a new random token.
+func newUploadToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// MemoryUploadStore is an UploadStore keeping uploads in memory. It is
+// meant for tests and single process setups with small uploads.
+type MemoryUploadStore struct {
+	// MaxAge is the time after which Get doesn't return uploads
+	// anymore. If zero, DefaultUploadMaxAge is used.
+	MaxAge  time.Duration
+	mutex   sync.Mutex
+	uploads map[string]memoryUpload
+}
+
+// memoryUpload is an upload stored by MemoryUploadStore.
+type memoryUpload struct {
+	Upload
+	stored time.Time
+}
+
+// NewMemoryUploadStore creates a new, empty MemoryUploadStore.
+func NewMemoryUploadStore() *MemoryUploadStore {
+	return &MemoryUploadStore{uploads: make(map[string]memoryUpload)}
+}
+
+func (s *MemoryUploadStore) Put(upload Upload) (string, error) {
+	token, err := newUploadToken()
+	if err != nil {
+		return "", err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.uploads[token] = memoryUpload{upload, time.Now()}
+	return token, nil
+}
+
+func (s *MemoryUploadStore) Get(token string) (Upload, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	upload, ok := s.uploads[token]
+	if !ok || time.Since(upload.stored) > uploadMaxAge(s.MaxAge) {
+		return Upload{}, ErrUploadNotFound
+	}
+	return upload.Upload, nil
+}
+
+func (s *MemoryUploadStore) Delete(token string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.uploads, token)
+	return nil
+}
+
+func (s *MemoryUploadStore) Cleanup() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	expired := time.Now().Add(-uploadMaxAge(s.MaxAge))
+	for token, upload := range s.uploads {
+		if upload.stored.Before(expired) {
+			delete(s.uploads, token)
+		}
+	}
+	return nil
+}
+
+// DirUploadStore is an UploadStore keeping uploads in a local
+// directory. Each upload is stored as a data file named by its token
+// and a metadata file with the suffix ".json".
+type DirUploadStore struct {
+	// Dir is the directory containing the uploads.
+	Dir string
+	// MaxAge is the time after which Get doesn't return uploads
+	// anymore. If zero, DefaultUploadMaxAge is used.
+	MaxAge time.Duration
+	mutex  sync.Mutex
+}
+
+// NewDirUploadStore creates a new DirUploadStore using the given
+// directory, which is created if it does not exist. If dir is empty, a
+// new temporary directory is used.
+func NewDirUploadStore(dir string) (*DirUploadStore, error) {
+	var err error
+	if dir == "" {
+		dir, err = ioutil.TempDir("", "htmlwidgets-uploads")
+	} else {
+		err = os.MkdirAll(dir, 0700)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &DirUploadStore{Dir: dir}, nil
+}
+
+// dirUploadMeta is the content of the metadata file of an upload.
+type dirUploadMeta struct {
+	Filename    string
+	ContentType string
+}
+
+func (s *DirUploadStore) Put(upload Upload) (string, error) {
+	token, err := newUploadToken()
+	if err != nil {
+		return "", err
+	}
+	meta, err := json.Marshal(dirUploadMeta{upload.Filename,
+		upload.ContentType})
+	if err != nil {
+		return "", err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	path := filepath.Join(s.Dir, token)
+	if err := ioutil.WriteFile(path, upload.Data, 0600); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(path+".json", meta, 0600); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *DirUploadStore) Get(token string) (Upload, error) {
+	if !tokenRe.MatchString(token) {
+		return Upload{}, ErrUploadNotFound
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	path := filepath.Join(s.Dir, token)
+	info, err := os.Stat(path + ".json")
+	if os.IsNotExist(err) ||
+		err == nil && time.Since(info.ModTime()) > uploadMaxAge(s.MaxAge) {
+		return Upload{}, ErrUploadNotFound
+	}
+	if err != nil {
+		return Upload{}, err
+	}
+	rawMeta, err := ioutil.ReadFile(path + ".json")
+	if os.IsNotExist(err) {
+		return Upload{}, ErrUploadNotFound
+	}
+	if err != nil {
+		return Upload{}, err
+	}
+	var meta dirUploadMeta
+	if err := json.Unmarshal(rawMeta, &meta); err != nil {
+		return Upload{}, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Upload{}, err
+	}
+	return Upload{
+		Filename:    meta.Filename,
+		ContentType: meta.ContentType,
+		Size:        int64(len(data)),
+		Data:        data,
+	}, nil
+}
+
+func (s *DirUploadStore) Delete(token string) error {
+	if !tokenRe.MatchString(token) {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.remove(token)
+}
+
+// remove removes the files of the given upload.
+func (s *DirUploadStore) remove(token string) error {
+	path := filepath.Join(s.Dir, token)
+	if err := os.Remove(path + ".json"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *DirUploadStore) Cleanup() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	infos, err := ioutil.ReadDir(s.Dir)
+	if err != nil {
+		return err
+	}
+	expired := time.Now().Add(-uploadMaxAge(s.MaxAge))
+	for _, info := range infos {
+		token := strings.TrimSuffix(info.Name(), ".json")
+		if tokenRe.MatchString(token) && info.ModTime().Before(expired) {
+			if err := s.remove(token); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testUploadStore performs common tests on the given store.
+func testUploadStore(t *testing.T, store UploadStore) {
+	upload := Upload{Filename: "b.txt", ContentType: "text/plain",
+		Size: int64(len(testText)), Data: testText}
+	token, err := store.Put(upload)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if !tokenRe.MatchString(token) {
+		t.Errorf("Invalid token %q", token)
+	}
+	if stored, err := store.Get(token); err != nil ||
+		!reflect.DeepEqual(stored, upload) {
+		t.Errorf("Get returned %#v (%v), expected %#v", stored, err, upload)
+	}
+	for _, invalid := range []string{"", "unknown", "../" + token} {
+		if _, err := store.Get(invalid); err != ErrUploadNotFound {
+			t.Errorf("Get(%q) returned %v, expected ErrUploadNotFound",
+				invalid, err)
+		}
+	}
+	if err := store.Cleanup(); err != nil {
+		t.Errorf("Cleanup failed: %v", err)
+	}
+	if _, err := store.Get(token); err != nil {
+		t.Errorf("Cleanup should keep new uploads: %v", err)
+	}
+	if err := store.Delete(token); err != nil {
+		t.Errorf("Delete failed: %v", err)
+	}
+	if _, err := store.Get(token); err != ErrUploadNotFound {
+		t.Errorf("Deleted upload should not be found: %v", err)
+	}
+}
+
+// testUploadStoreExpiry checks that the given store with a MaxAge of
+// one millisecond doesn't return expired uploads and that Cleanup
+// removes them. setMaxAge sets the MaxAge of the store.
+func testUploadStoreExpiry(t *testing.T, store UploadStore,
+	setMaxAge func(time.Duration)) {
+	token, err := store.Put(Upload{Filename: "b.txt", Data: testText})
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := store.Get(token); err != ErrUploadNotFound {
+		t.Errorf("Get should not return expired uploads: %v", err)
+	}
+	if err := store.Cleanup(); err != nil {
+		t.Errorf("Cleanup failed: %v", err)
+	}
+	setMaxAge(time.Hour)
+	if _, err := store.Get(token); err != ErrUploadNotFound {
+		t.Errorf("Cleanup should remove expired uploads: %v", err)
+	}
+}
+
+func TestMemoryUploadStore(t *testing.T) {
+	testUploadStore(t, NewMemoryUploadStore())
+	store := NewMemoryUploadStore()
+	store.MaxAge = time.Millisecond
+	testUploadStoreExpiry(t, store, func(maxAge time.Duration) {
+		store.MaxAge = maxAge
+	})
+}
+
+func TestDirUploadStore(t *testing.T) {
+	store, err := NewDirUploadStore("")
+	if err != nil {
+		t.Fatalf("NewDirUploadStore failed: %v", err)
+	}
+	defer os.RemoveAll(store.Dir)
+	testUploadStore(t, store)
+	store.MaxAge = time.Millisecond
+	testUploadStoreExpiry(t, store, func(maxAge time.Duration) {
+		store.MaxAge = maxAge
+	})
+}
@@ -0,0 +1,531 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TagName is the name of the struct tag read by NewFormFromStruct.
+const TagName = "htmlwidgets"
+
+// widgetFactories maps the widget names usable in struct tags to
+// functions creating the widget.
+var widgetFactories = map[string]func() Widget{
+	"text":        func() Widget { return new(TextWidget) },
+	"password":    func() Widget { return new(PasswordWidget) },
+	"textarea":    func() Widget { return new(TextAreaWidget) },
+	"checkbox":    func() Widget { return new(BoolWidget) },
+	"integer":     func() Widget { return new(IntegerWidget) },
+	"number":      func() Widget { return new(NumberWidget) },
+	"select":      func() Widget { return new(SelectWidget) },
+	"multiselect": func() Widget { return new(MultiSelectWidget) },
+	"checkboxes":  func() Widget { return &MultiSelectWidget{Checkboxes: true} },
+	"radio":       func() Widget { return new(RadioWidget) },
+	"hidden":      func() Widget { return new(HiddenWidget) },
+	"file":        func() Widget { return new(FileWidget) },
+	"list":        func() Widget { return new(ListWidget) },
+	"time":        func() Widget { return new(TimeWidget) },
+	"date":        func() Widget { return new(DateWidget) },
+	"timeofday":   func() Widget { return new(TimeOfDayWidget) },
+	"daterange":   func() Widget { return new(DateRangeWidget) },
+	"duration":    func() Widget { return new(DurationWidget) },
+}
+
+// NewFormFromStruct creates a new Form with data stored in the given
+// pointer to a structure and adds a widget for each exported field.
+//
+// The widgets are configured by the "htmlwidgets" struct tag, which
+// contains a comma separated list of options:
+//
+//	label=Name          the label of the widget (default: the field name)
+//	description=Text    the description of the widget
+//	widget=text         the widget to use (default: chosen by field type)
+//	required            values must not be empty
+//	minlength=1         the minimum length of non-empty values, use
+//	                    required to reject empty ones
+//	maxlength=10        the maximum length of values
+//	min=0               the minimum of numeric values or of dates
+//	                    ("2006-01-02"), times ("15:04") and durations
+//	                    ("1h30m")
+//	max=99              the maximum of the same values
+//	step=0.5            the step of values of number widgets
+//	regexp=^\w+$        a regular expression values have to match
+//	email               values must be email addresses
+//	url                 values must be absolute URLs
+//	error=Message       the message of the above validators
+//	options=a:A|b:B     the options of a select, multiselect or radio
+//	                    widget (value:description)
+//	minchoices=1        the minimum number of options of a multiselect
+//	maxchoices=3        the maximum number of options of a multiselect
+//	addlabel=Add        the label of a list's add button
+//	removelabel=Remove  the label of a list's remove buttons
+//	minitems=1          the minimum number of items of a list
+//	maxitems=5          the maximum number of items of a list
+//
+// Values containing commas must be quoted with single quotes, in which
+// quotes are doubled:
+//
+//	regexp='^\d{1,3}$',error='Sorry, that''s not a number.'
+//
+// Fields tagged with "-" are skipped. Without a widget option, string
+// fields get a TextWidget, bools a BoolWidget, ints an IntegerWidget,
+// other numbers a NumberWidget, time.Time a TimeWidget, Date a
+// DateWidget, TimeOfDay a TimeOfDayWidget, DateRange a DateRangeWidget,
+// time.Duration a DurationWidget and slices a ListWidget with an inner
+// widget chosen by the element type. Pointers get the widget of their
+// element type, except for pointers to other structs. Fields of
+// embedded structs are added as if they were fields of the outer
+// struct, fields of other struct fields are added with the dotted id
+// "Outer.Inner". Struct elements of slices and tagged struct fields
+// get a StructWidget. Struct types containing themselves, e.g. in a
+// slice of children, can't be expanded. File widgets can't be used in
+// StructWidgets, see FileWidget.
+//
+// It panics if data is not a pointer to a struct, if a tag is invalid
+// or if no widget can be chosen for a field.
+func NewFormFromStruct(data interface{}) *Form {
+	dataType := reflect.TypeOf(data)
+	if dataType == nil || dataType.Kind() != reflect.Ptr ||
+		dataType.Elem().Kind() != reflect.Struct {
+		panic("NewFormFromStruct(data) expects data to be a pointer to a struct.")
+	}
+	form, err := NewFormFromStructE(data)
+	if err != nil {
+		panic(err.Error())
+	}
+	return form
+}
+
+// NewFormFromStructE creates a new Form like NewFormFromStruct, but
+// returns an error instead of panicking.
+func NewFormFromStructE(data interface{}) (*Form, error) {
+	dataType := reflect.TypeOf(data)
+	if dataType == nil || dataType.Kind() != reflect.Ptr ||
+		dataType.Elem().Kind() != reflect.Struct {
+		return nil, fieldError("", ErrTypeMismatch,
+			"expected a pointer to a struct, got %T", data)
+	}
+	form := NewForm(data)
+	err := addStructWidgets(form, dataType.Elem(), "",
+		make(map[reflect.Type]bool))
+	if err != nil {
+		return nil, fmt.Errorf("htmlwidgets: %v", err)
+	}
+	return form, nil
+}
+
+// widgetAdder is implemented by Form and StructWidget.
+type widgetAdder interface {
+	AddWidget(widget Widget, id, label, description string) Widget
+}
+
+// addStructWidgets adds widgets for all fields of the given struct
+// type to the form or struct widget, prefixing their ids with prefix.
+//
+// expanding contains the struct types whose fields are being added to
+// detect types containing themselves.
+func addStructWidgets(form widgetAdder, structType reflect.Type, prefix string,
+	expanding map[reflect.Type]bool) error {
+	if expanding[structType] {
+		return fmt.Errorf("recursive struct type %v", structType)
+	}
+	expanding[structType] = true
+	defer delete(expanding, structType)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get(TagName)
+		if tag == "-" {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct && !isTemporalType(field.Type) &&
+			tag == "" {
+			subPrefix := prefix + field.Name + "."
+			if field.Anonymous {
+				subPrefix = prefix
+			}
+			err := addStructWidgets(form, field.Type, subPrefix, expanding)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		options, err := parseTag(tag)
+		if err != nil {
+			return fmt.Errorf("invalid tag of field %q: %v",
+				prefix+field.Name, err)
+		}
+		widget, err := widgetForField(field.Type, options, expanding)
+		if err != nil {
+			return fmt.Errorf("field %q: %v", prefix+field.Name, err)
+		}
+		// StructWidgets don't pass uploaded files to their children.
+		if _, nested := form.(*StructWidget); nested {
+			if _, ok := widget.(MultipartWidget); ok {
+				return fmt.Errorf("field %q: %T can't be nested in a StructWidget",
+					prefix+field.Name, widget)
+			}
+		}
+		label, ok := options.Get("label")
+		if !ok {
+			label = field.Name
+		}
+		description, _ := options.Get("description")
+		form.AddWidget(widget, prefix+field.Name, label, description)
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// tagOption is a single option of a htmlwidgets struct tag.
+type tagOption struct {
+	Key, Value string
+}
+
+// tagOptions are the options of a struct tag in the order of their
+// appearance.
+type tagOptions []tagOption
+
+// Get returns the value of the option with the given key.
+func (o tagOptions) Get(key string) (string, bool) {
+	for _, option := range o {
+		if option.Key == key {
+			return option.Value, true
+		}
+	}
+	return "", false
+}
+
+// parseTag parses the options of a htmlwidgets struct tag. Options
+// without a value have an empty value.
+func parseTag(tag string) (tagOptions, error) {
+	var options tagOptions
+	for rest := tag; rest != ""; {
+		end := strings.IndexAny(rest, "=,")
+		if end < 0 {
+			end = len(rest)
+		}
+		key := strings.TrimSpace(rest[:end])
+		rest = rest[end:]
+		var value string
+		if strings.HasPrefix(rest, "=") {
+			var err error
+			if value, rest, err = parseTagValue(rest[1:]); err != nil {
+				return nil, fmt.Errorf("option %q: %v", key, err)
+			}
+		}
+		if key == "" {
+			return nil, fmt.Errorf("empty option in %q", tag)
+		}
+		if _, ok := options.Get(key); ok {
+			return nil, fmt.Errorf("duplicate option %q", key)
+		}
+		options = append(options, tagOption{key, value})
+		if rest != "" {
+			if rest[0] != ',' {
+				return nil, fmt.Errorf("option %q: expected comma after quoted value",
+					key)
+			}
+			rest = rest[1:]
+			if rest == "" {
+				return nil, fmt.Errorf("empty option in %q", tag)
+			}
+		}
+	}
+	return options, nil
+}
+
+// parseTagValue parses the value of an option at the start of the
+// given part of a struct tag. Values in single quotes may contain
+// commas and doubled quotes. It returns the value and the rest of the
+// tag starting with the comma after the value.
+func parseTagValue(s string) (value, rest string, err error) {
+	if !strings.HasPrefix(s, "'") {
+		end := strings.Index(s, ",")
+		if end < 0 {
+			return s, "", nil
+		}
+		return s[:end], s[end:], nil
+	}
+	var buf []byte
+	for i := 1; i < len(s); i++ {
+		if s[i] != '\'' {
+			buf = append(buf, s[i])
+		} else if i+1 < len(s) && s[i+1] == '\'' {
+			buf = append(buf, '\'')
+			i++
+		} else {
+			return string(buf), s[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("unterminated quote")
+}
+
+// widgetForField creates and configures the widget for a field of the
+// given type.
+func widgetForField(fieldType reflect.Type, options tagOptions,
+	expanding map[reflect.Type]bool) (Widget, error) {
+	var widget Widget
+	if name, ok := options.Get("widget"); ok {
+		factory, ok := widgetFactories[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown widget %q", name)
+		}
+		widget = factory()
+	} else {
+		var err error
+		if widget, err = defaultWidget(fieldType, expanding); err != nil {
+			return nil, err
+		}
+		if widget == nil {
+			return nil, fmt.Errorf("no default widget for type %v", fieldType)
+		}
+	}
+	if list, ok := widget.(*ListWidget); ok && list.InnerWidget == nil {
+		if fieldType.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("list widget needs a slice, got %v", fieldType)
+		}
+		var err error
+		list.InnerWidget, err = defaultWidget(fieldType.Elem(), expanding)
+		if err != nil {
+			return nil, err
+		}
+		if list.InnerWidget == nil {
+			return nil, fmt.Errorf("no default widget for list element type %v",
+				fieldType.Elem())
+		}
+	}
+	message, _ := options.Get("error")
+	for _, option := range options {
+		err := configureWidget(widget, fieldType, option.Key, option.Value,
+			message)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return widget, nil
+}
+
+// defaultWidget returns a new widget suitable for the given type or
+// nil if there is none. Struct types are expanded into StructWidgets,
+// which fails for types in expanding.
+func defaultWidget(t reflect.Type, expanding map[reflect.Type]bool) (
+	Widget, error) {
+	switch t {
+	case timeType:
+		return new(TimeWidget), nil
+	case dateType:
+		return new(DateWidget), nil
+	case timeOfDayType:
+		return new(TimeOfDayWidget), nil
+	case dateRangeType:
+		return new(DateRangeWidget), nil
+	case durationType:
+		return new(DurationWidget), nil
+	case reflect.TypeOf(""):
+		return new(TextWidget), nil
+	case reflect.TypeOf(false):
+		return new(BoolWidget), nil
+	case reflect.TypeOf(0):
+		return new(IntegerWidget), nil
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		// Nil pointers to structs can't be filled by child widgets.
+		if t.Elem().Kind() == reflect.Struct && !isTemporalType(t.Elem()) {
+			return nil, nil
+		}
+		return defaultWidget(t.Elem(), expanding)
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Float32, reflect.Float64:
+		return new(NumberWidget), nil
+	case reflect.Slice:
+		inner, err := defaultWidget(t.Elem(), expanding)
+		if inner == nil {
+			return nil, err
+		}
+		return &ListWidget{InnerWidget: inner}, nil
+	case reflect.Struct:
+		widget := new(StructWidget)
+		if err := addStructWidgets(widget, t, "", expanding); err != nil {
+			return nil, err
+		}
+		return widget, nil
+	}
+	return nil, nil
+}
+
+// configureWidget applies a single tag option to the given widget for
+// a field of the given type. Validators created for the option use the
+// given error message.
+func configureWidget(widget Widget, fieldType reflect.Type, key, value,
+	message string) error {
+	base := widget.Base()
+	var min, max, step *string
+	switch w := widget.(type) {
+	case *NumberWidget:
+		min, max, step = &w.Min, &w.Max, &w.Step
+	case *IntegerWidget:
+		min, max, step = &w.Min, &w.Max, &w.Step
+	}
+	if handled, err := configureTemporalWidget(widget, key, value); handled {
+		return err
+	}
+	if min != nil {
+		switch key {
+		case "min", "max", "step":
+			if _, ok := new(big.Rat).SetString(value); !ok {
+				return fmt.Errorf("option %q expects a number, got %q", key, value)
+			}
+			switch key {
+			case "min":
+				*min = value
+			case "max":
+				*max = value
+			case "step":
+				*step = value
+			}
+			return nil
+		}
+	}
+	switch key {
+	case "label", "description", "widget", "error":
+		return nil
+	case "required":
+		base.Validators = append(base.Validators, Required(message))
+		return nil
+	case "minlength", "maxlength":
+		var n int
+		if err := parseIntOption(key, value, &n); err != nil {
+			return err
+		}
+		if key == "minlength" {
+			base.Validators = append(base.Validators, MinLength(n, message))
+		} else {
+			base.Validators = append(base.Validators, MaxLength(n, message))
+		}
+		return nil
+	case "min", "max":
+		kind := indirectType(fieldType).Kind()
+		_, isNumber := widget.(*NumberWidget)
+		// NumberWidgets fill decimal strings, which Range compares as
+		// numbers.
+		if !isRangeKind(kind) && !(isNumber && kind == reflect.String) {
+			return fmt.Errorf("option %q needs a numeric field, got %v", key,
+				fieldType)
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("option %q expects a number, got %q", key, value)
+		}
+		if key == "min" {
+			base.Validators = append(base.Validators,
+				Range(n, math.Inf(1), message))
+		} else {
+			base.Validators = append(base.Validators,
+				Range(math.Inf(-1), n, message))
+		}
+		return nil
+	case "regexp":
+		if _, err := regexp.Compile(value); err != nil {
+			return fmt.Errorf("option %q: %v", key, err)
+		}
+		base.Validators = append(base.Validators, Regexp(value, message))
+		return nil
+	case "email":
+		base.Validators = append(base.Validators, Email(message))
+		return nil
+	case "url":
+		base.Validators = append(base.Validators, URL(message))
+		return nil
+	}
+	switch w := widget.(type) {
+	case *SelectWidget:
+		if key == "options" {
+			w.Options = parseOptionsTag(value)
+			return nil
+		}
+	case *RadioWidget:
+		if key == "options" {
+			w.Options = parseOptionsTag(value)
+			return nil
+		}
+	case *MultiSelectWidget:
+		switch key {
+		case "options":
+			w.Options = parseOptionsTag(value)
+			return nil
+		case "minchoices":
+			return parseIntOption(key, value, &w.MinChoices)
+		case "maxchoices":
+			return parseIntOption(key, value, &w.MaxChoices)
+		}
+	case *ListWidget:
+		switch key {
+		case "addlabel":
+			w.AddLabel = value
+			return nil
+		case "removelabel":
+			w.RemoveLabel = value
+			return nil
+		case "minitems":
+			return parseIntOption(key, value, &w.MinItems)
+		case "maxitems":
+			return parseIntOption(key, value, &w.MaxItems)
+		}
+	}
+	return fmt.Errorf("option %q is not supported by %T", key, widget)
+}
+
+// parseOptionsTag parses the options of a select tag option.
+func parseOptionsTag(value string) []SelectOption {
+	var options []SelectOption
+	for _, option := range strings.Split(value, "|") {
+		parts := strings.SplitN(option, ":", 2)
+		if len(parts) == 1 {
+			parts = append(parts, parts[0])
+		}
+		options = append(options, SelectOption{
+			Value: parts[0], Description: parts[1]})
+	}
+	return options
+}
+
+// parseIntOption parses the integer value of a tag option into target.
+func parseIntOption(key, value string, target *int) error {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("option %q expects an integer, got %q", key, value)
+	}
+	*target = v
+	return nil
+}
@@ -0,0 +1,338 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type TestStructFormAddress struct {
+	City string `htmlwidgets:"label=City,required,error=City required!"`
+}
+
+type TestStructFormData struct {
+	TestAppDataEmbed
+	Name     string `htmlwidgets:"label=Name,description=Your name,widget=text,minlength=3,error=Too short!"`
+	Password string `htmlwidgets:"widget=password"`
+	Alive    bool
+	Age      int
+	Born     time.Time
+	Tags     []string `htmlwidgets:"addlabel=Add,removelabel=Remove,maxitems=5"`
+	Color    string   `htmlwidgets:"widget=select,options=red:Red|blue:Blue"`
+	Address  TestStructFormAddress
+	Ignored  string `htmlwidgets:"-"`
+	internal string
+}
+
+func TestNewFormFromStruct(t *testing.T) {
+	data := TestStructFormData{}
+	form := NewFormFromStruct(&data)
+	expected := []struct {
+		Id, Label, Description string
+		Type                   reflect.Type
+	}{
+		{"Title", "Title", "", reflect.TypeOf(&TextWidget{})},
+		{"Name", "Name", "Your name", reflect.TypeOf(&TextWidget{})},
+		{"Password", "Password", "", reflect.TypeOf(&PasswordWidget{})},
+		{"Alive", "Alive", "", reflect.TypeOf(&BoolWidget{})},
+		{"Age", "Age", "", reflect.TypeOf(&IntegerWidget{})},
+		{"Born", "Born", "", reflect.TypeOf(&TimeWidget{})},
+		{"Tags", "Tags", "", reflect.TypeOf(&ListWidget{})},
+		{"Color", "Color", "", reflect.TypeOf(&SelectWidget{})},
+		{"Address.City", "City", "", reflect.TypeOf(&TextWidget{})},
+	}
+	if len(form.Widgets) != len(expected) {
+		t.Fatalf("Form has %v widgets, expected %v", len(form.Widgets),
+			len(expected))
+	}
+	for i, test := range expected {
+		widget := form.Widgets[i]
+		base := widget.Base()
+		if base.Id != test.Id || base.Label != test.Label ||
+			base.Description != test.Description ||
+			reflect.TypeOf(widget) != test.Type {
+			t.Errorf("Widget %v is %T %q (%q, %q), expected %v %q (%q, %q)", i,
+				widget, base.Id, base.Label, base.Description, test.Type, test.Id,
+				test.Label, test.Description)
+		}
+	}
+	if validators := form.WidgetById("Name").Base().Validators; len(validators) != 1 {
+		t.Errorf("Name widget should have one validator, has %v", len(validators))
+	}
+	tags := form.WidgetById("Tags").(*ListWidget)
+	if _, ok := tags.InnerWidget.(*TextWidget); !ok ||
+		tags.AddLabel != "Add" || tags.RemoveLabel != "Remove" ||
+		tags.MaxItems != 5 {
+		t.Errorf("Tags widget not configured by tag: %#v", tags)
+	}
+	color := form.WidgetById("Color").(*SelectWidget)
+	if !reflect.DeepEqual(color.Options, []SelectOption{
+		SelectOption{"red", "Red", false},
+		SelectOption{"blue", "Blue", false},
+	}) {
+		t.Errorf("Select options not configured by tag: %#v", color.Options)
+	}
+
+	vals := url.Values{
+		"Title":        []string{"Dr."},
+		"Name":         []string{"Fo"},
+		"Alive":        []string{"true"},
+		"Age":          []string{"42"},
+		"Born":         []string{"1985-04-10T08:10"},
+		"Tags.0":       []string{"a"},
+		"Color":        []string{"blue"},
+		"Address.City": []string{""},
+	}
+	if form.Fill(vals) {
+		t.Errorf("Fill should fail for missing city")
+	}
+	if errors := form.WidgetById("Name").Base().Errors; !reflect.DeepEqual(
+		errors, []string{"Too short!"}) {
+		t.Errorf("Name errors are %v", errors)
+	}
+	if errors := form.WidgetById("Address.City").Base().Errors; !reflect.DeepEqual(
+		errors, []string{"City required!"}) {
+		t.Errorf("City errors are %v", errors)
+	}
+	vals["Name"] = []string{"Foo"}
+	vals["Address.City"] = []string{"Bar"}
+	if !form.Fill(vals) {
+		t.Errorf("Fill returned false. Errors: %v", form.RenderData().Errors)
+	}
+	if data.Title != "Dr." || data.Name != "Foo" || !data.Alive ||
+		data.Age != 42 || data.Born.Year() != 1985 ||
+		!reflect.DeepEqual(data.Tags, []string{"a"}) || data.Color != "blue" ||
+		data.Address.City != "Bar" {
+		t.Errorf("Filled data is %#v", data)
+	}
+}
+
+func TestNewFormFromStructPointers(t *testing.T) {
+	data := struct {
+		Name  *string
+		Age   *int
+		Alive *bool
+		Born  *time.Time
+	}{}
+	form := NewFormFromStruct(&data)
+	if err := form.Verify(); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	for i, widget := range form.RenderData().Widgets {
+		if widget.Data != nil && widget.Data != "" {
+			t.Errorf("Widget %d of nil pointer has data %#v", i, widget.Data)
+		}
+	}
+	form.Fill(url.Values{
+		"Name":  []string{"Max"},
+		"Age":   []string{"42"},
+		"Alive": []string{"true"},
+		"Born":  []string{"2000-01-02T03:04"},
+	})
+	born := time.Date(2000, time.January, 2, 3, 4, 0, 0, time.UTC)
+	if data.Name == nil || *data.Name != "Max" || data.Age == nil ||
+		*data.Age != 42 || data.Alive == nil || !*data.Alive ||
+		data.Born == nil || !data.Born.Equal(born) {
+		t.Errorf("Filled data is %+v", data)
+	}
+	rd := form.RenderData()
+	if rd.Widgets[0].Data != "Max" || rd.Widgets[1].Data != 42 {
+		t.Errorf("Invalid render data %v", rd.Widgets)
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		Tag      string
+		Expected tagOptions
+		Valid    bool
+	}{
+		{"", nil, true},
+		{"required,label=Name", tagOptions{{"required", ""},
+			{"label", "Name"}}, true},
+		{`regexp='^\d{1,3}$',error='Sorry, that''s wrong.'`,
+			tagOptions{{"regexp", `^\d{1,3}$`},
+				{"error", "Sorry, that's wrong."}}, true},
+		{"label='',required", tagOptions{{"label", ""},
+			{"required", ""}}, true},
+		{"label=a=b", tagOptions{{"label", "a=b"}}, true},
+		{"error='Sorry", nil, false},
+		{"error='Sorry'x", nil, false},
+		{"required,", nil, false},
+		{",required", nil, false},
+	}
+	for i, test := range tests {
+		options, err := parseTag(test.Tag)
+		if (err == nil) != test.Valid ||
+			test.Valid && !reflect.DeepEqual(options, test.Expected) {
+			t.Errorf("Test %d: Parsed %q as %v, %v", i, test.Tag, options,
+				err)
+		}
+	}
+}
+
+func TestNewFormFromStructInvalid(t *testing.T) {
+	tests := []interface{}{
+		TestStructFormData{},
+		&struct {
+			Name string `htmlwidgets:"widget=unknown"`
+		}{},
+		&struct {
+			Name string `htmlwidgets:"minlength=foo"`
+		}{},
+		&struct {
+			Alive bool `htmlwidgets:"addlabel=Add"`
+		}{},
+		&struct {
+			Name string `htmlwidgets:"regexp=("`
+		}{},
+		&struct {
+			Name string `htmlwidgets:"label=a,label=b"`
+		}{},
+		&struct {
+			Extra map[string]string
+		}{},
+		&struct {
+			Name string `htmlwidgets:"min=3"`
+		}{},
+		&struct {
+			Tags []int `htmlwidgets:"max=3"`
+		}{},
+		&testNode{},
+		&struct {
+			Document struct {
+				File []byte `htmlwidgets:"widget=file"`
+			} `htmlwidgets:"label=Document"`
+		}{},
+	}
+	for i, data := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Test %v: NewFormFromStruct did not panic", i)
+				}
+			}()
+			NewFormFromStruct(data)
+		}()
+	}
+}
+
+func TestNewFormFromStructDecimalRange(t *testing.T) {
+	data := struct {
+		Price string `htmlwidgets:"widget=number,min=0,max=100"`
+	}{}
+	form := NewFormFromStruct(&data)
+	if form.Fill(url.Values{"Price": []string{"100.5"}}) {
+		t.Errorf("Fill accepted a price above max")
+	}
+	if !form.Fill(url.Values{"Price": []string{"99.5"}}) || data.Price != "99.5" {
+		t.Errorf("Fill failed, price is %q", data.Price)
+	}
+}
+
+func TestNewFormFromStructMinLength(t *testing.T) {
+	data := struct {
+		Nick string `htmlwidgets:"minlength=3,error=Too short!"`
+		Name string `htmlwidgets:"required,minlength=3,error=Too short!"`
+	}{}
+	form := NewFormFromStruct(&data)
+	tests := []struct {
+		Nick, Name string
+		Errors     map[string][]string
+	}{
+		{"", "", map[string][]string{"Name": []string{"Too short!"}}},
+		{"ab", "abc", map[string][]string{"Nick": []string{"Too short!"}}},
+		{"", "ab", map[string][]string{"Name": []string{"Too short!"}}},
+		{"", "abc", map[string][]string{}},
+	}
+	for i, test := range tests {
+		result := form.FillValues(url.Values{
+			"Nick": []string{test.Nick},
+			"Name": []string{test.Name},
+		})
+		if result.Valid != (len(test.Errors) == 0) ||
+			!reflect.DeepEqual(result.Errors, test.Errors) {
+			t.Errorf("Test %d: result is %v, expected errors %v", i, result,
+				test.Errors)
+		}
+	}
+}
+
+// testNode is a recursive struct type.
+type testNode struct {
+	Name     string
+	Children []testNode
+}
+
+func TestNewFormFromStructRecursive(t *testing.T) {
+	if _, err := NewFormFromStructE(&testNode{}); err == nil ||
+		!strings.Contains(err.Error(), "recursive struct type") {
+		t.Errorf("NewFormFromStructE returned error %v", err)
+	}
+	data := struct {
+		Root testNode `htmlwidgets:"label=Root"`
+	}{}
+	if _, err := NewFormFromStructE(&data); err == nil {
+		t.Errorf("NewFormFromStructE accepted a recursive struct field")
+	}
+	// Struct types used twice without recursion are expanded.
+	type pair struct{ First, Second TestAddress }
+	if _, err := NewFormFromStructE(&pair{}); err != nil {
+		t.Errorf("NewFormFromStructE failed: %v", err)
+	}
+	if _, err := NewFormFromStructE(testNode{}); err == nil {
+		t.Errorf("NewFormFromStructE accepted a struct value")
+	}
+}
+
+func TestNewFormFromStructSlices(t *testing.T) {
+	type address struct {
+		Street string `htmlwidgets:"label=Street,required"`
+		City   string
+	}
+	data := struct {
+		Addresses []address `htmlwidgets:"addlabel=Add"`
+		Home      address   `htmlwidgets:"label=Home"`
+	}{}
+	form := NewFormFromStruct(&data)
+	list, ok := form.WidgetById("Addresses").(*ListWidget)
+	if !ok {
+		t.Fatalf("Addresses should get a ListWidget")
+	}
+	inner, ok := list.InnerWidget.(*StructWidget)
+	if !ok || len(inner.Widgets) != 2 ||
+		inner.Widgets[0].Base().Label != "Street" {
+		t.Fatalf("Inner widget should be a StructWidget, is %#v",
+			list.InnerWidget)
+	}
+	if _, ok := form.WidgetById("Home").(*StructWidget); !ok {
+		t.Errorf("Tagged struct field should get a StructWidget")
+	}
+	form.Fill(url.Values{
+		"Addresses.0.Street": []string{"Main St"},
+		"Addresses.0.City":   []string{"Berlin"},
+		"Home.City":          []string{"Paris"},
+	})
+	if !reflect.DeepEqual(data.Addresses, []address{{"Main St", "Berlin"}}) ||
+		data.Home.City != "Paris" {
+		t.Errorf("Filled data is %v", data)
+	}
+}
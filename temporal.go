@@ -0,0 +1,818 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layouts of the values of HTML5 date and time inputs.
+const (
+	DateLayout         = "2006-01-02"
+	TimeOfDayLayout    = "15:04"
+	TimeOfDaySecLayout = "15:04:05"
+)
+
+// Date is a civil date without time and timezone, so it doesn't change
+// when converted between timezones.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date of t in the location of t.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{year, month, day}
+}
+
+// ParseDate parses a date in the format "2006-01-02".
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Date{}, err
+	}
+	return DateOf(t), nil
+}
+
+// String returns the date in the format "2006-01-02" or an empty string
+// for the zero date.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Time(time.UTC).Format(DateLayout)
+}
+
+// Time returns the time at midnight of the date in the given location.
+func (d Date) Time(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// IsZero returns true for the zero date.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// Before returns true if d is before other.
+func (d Date) Before(other Date) bool {
+	if d.Year != other.Year {
+		return d.Year < other.Year
+	}
+	if d.Month != other.Month {
+		return d.Month < other.Month
+	}
+	return d.Day < other.Day
+}
+
+// After returns true if d is after other.
+func (d Date) After(other Date) bool {
+	return other.Before(d)
+}
+
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Date) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*d = Date{}
+		return nil
+	}
+	date, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+// TimeOfDay is a time of day without date and timezone.
+type TimeOfDay struct {
+	Hour, Minute, Second int
+}
+
+// TimeOfDayOf returns the time of day of t in the location of t.
+func TimeOfDayOf(t time.Time) TimeOfDay {
+	return TimeOfDay{t.Hour(), t.Minute(), t.Second()}
+}
+
+// ParseTimeOfDay parses a time of day in the format "15:04" or
+// "15:04:05".
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	t, err := parseTime(s, time.UTC, TimeOfDayLayout, TimeOfDaySecLayout)
+	if err != nil {
+		return TimeOfDay{}, err
+	}
+	return TimeOfDayOf(t), nil
+}
+
+// String returns the time of day in the format "15:04" or "15:04:05" if
+// it has seconds.
+func (t TimeOfDay) String() string {
+	if t.Second != 0 {
+		return t.time().Format(TimeOfDaySecLayout)
+	}
+	return t.time().Format(TimeOfDayLayout)
+}
+
+// time returns the time of day on the zero date.
+func (t TimeOfDay) time() time.Time {
+	return time.Date(0, 1, 1, t.Hour, t.Minute, t.Second, 0, time.UTC)
+}
+
+// On returns the time of day at the given date in the given location.
+func (t TimeOfDay) On(d Date, loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, t.Hour, t.Minute, t.Second, 0,
+		loc)
+}
+
+// Before returns true if t is before other.
+func (t TimeOfDay) Before(other TimeOfDay) bool {
+	return t.time().Before(other.time())
+}
+
+// After returns true if t is after other.
+func (t TimeOfDay) After(other TimeOfDay) bool {
+	return other.Before(t)
+}
+
+func (t TimeOfDay) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *TimeOfDay) UnmarshalText(text []byte) error {
+	parsed, err := ParseTimeOfDay(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// DateRange is a range of dates including Start and End. Zero dates
+// mean open ends.
+type DateRange struct {
+	Start, End Date
+}
+
+// Contains returns true if the given date is within the range.
+func (r DateRange) Contains(d Date) bool {
+	return (r.Start.IsZero() || !d.Before(r.Start)) &&
+		(r.End.IsZero() || !d.After(r.End))
+}
+
+var (
+	dateType      = reflect.TypeOf(Date{})
+	timeOfDayType = reflect.TypeOf(TimeOfDay{})
+	dateRangeType = reflect.TypeOf(DateRange{})
+	durationType  = reflect.TypeOf(time.Duration(0))
+)
+
+// isTemporalType returns true for the struct types of dates and times,
+// which get their own widgets instead of a StructWidget.
+func isTemporalType(t reflect.Type) bool {
+	return t == timeType || t == dateType || t == timeOfDayType ||
+		t == dateRangeType
+}
+
+// configureTemporalWidget applies the "min" and "max" struct tag
+// options to date, time of day, date range and duration widgets.
+// handled is false for other widgets and options.
+func configureTemporalWidget(widget Widget, key, value string) (
+	handled bool, err error) {
+	if key != "min" && key != "max" {
+		return false, nil
+	}
+	var min, max interface{}
+	switch w := widget.(type) {
+	case *DateWidget:
+		min, max = &w.Min, &w.Max
+	case *DateRangeWidget:
+		min, max = &w.Min, &w.Max
+	case *TimeOfDayWidget:
+		min, max = &w.Min, &w.Max
+	case *DurationWidget:
+		min, max = &w.Min, &w.Max
+	default:
+		return false, nil
+	}
+	target := min
+	if key == "max" {
+		target = max
+	}
+	switch target := target.(type) {
+	case *Date:
+		*target, err = ParseDate(value)
+	case *TimeOfDay:
+		*target, err = ParseTimeOfDay(value)
+	case **time.Duration:
+		var d time.Duration
+		d, err = time.ParseDuration(value)
+		*target = &d
+	}
+	if err != nil {
+		return true, fmt.Errorf("option %q: %v", key, err)
+	}
+	return true, nil
+}
+
+// TemporalRenderData is the Data of the render data of DateWidget,
+// TimeOfDayWidget and DurationWidget.
+type TemporalRenderData struct {
+	// Value is the formatted value or the submitted value if it could
+	// not be parsed.
+	Value string
+	// Min and Max are the limits of the widget in the format of the
+	// HTML5 input and may be used as its attributes. Empty values mean
+	// no limit.
+	Min, Max string
+}
+
+// fieldInterface returns the value of the given field without pointer
+// indirection or nil for nil pointers.
+func fieldInterface(value reflect.Value) interface{} {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return nil
+	}
+	return value.Interface()
+}
+
+// localLayouts returns the layouts of the form's locale selected by
+// layouts or nil if the form has no locale format.
+func (f *Form) localLayouts(layouts func(*LocaleFormat) []string) []string {
+	if lf := f.localeFormat(); lf != nil {
+		return layouts(lf)
+	}
+	return nil
+}
+
+func dateLayouts(lf *LocaleFormat) []string { return lf.DateLayouts }
+
+func timeLayouts(lf *LocaleFormat) []string { return lf.TimeLayouts }
+
+// parseDate parses a date in the format of the form's locale or the
+// format of HTML5 date inputs.
+func (f *Form) parseDate(s string) (Date, error) {
+	layouts := append(f.localLayouts(dateLayouts), DateLayout)
+	t, err := parseTime(s, time.UTC, layouts...)
+	if err != nil {
+		return Date{}, err
+	}
+	return DateOf(t), nil
+}
+
+// formatDate formats a date in the format of the form's locale or the
+// format of HTML5 date inputs. localized is true for the locale's
+// format.
+func (f *Form) formatDate(d Date) (s string, localized bool) {
+	layouts := f.localLayouts(dateLayouts)
+	if len(layouts) == 0 {
+		return d.String(), false
+	}
+	if d.IsZero() {
+		return "", true
+	}
+	return d.Time(time.UTC).Format(layouts[0]), true
+}
+
+// parseTimeOfDay parses a time of day in the format of the form's
+// locale or the format of HTML5 time inputs.
+func (f *Form) parseTimeOfDay(s string) (TimeOfDay, error) {
+	layouts := append(f.localLayouts(timeLayouts), TimeOfDayLayout,
+		TimeOfDaySecLayout)
+	t, err := parseTime(s, time.UTC, layouts...)
+	if err != nil {
+		return TimeOfDay{}, err
+	}
+	return TimeOfDayOf(t), nil
+}
+
+// formatTimeOfDay formats a time of day in the format of the form's
+// locale or the format of HTML5 time inputs. localized is true for the
+// locale's format.
+func (f *Form) formatTimeOfDay(t TimeOfDay) (s string, localized bool) {
+	layouts := f.localLayouts(timeLayouts)
+	if len(layouts) == 0 {
+		return t.String(), false
+	}
+	return t.time().Format(layouts[0]), true
+}
+
+// dateOf returns the date of a Date or time.Time value. Zero times and
+// other values give the zero date.
+func dateOf(value interface{}) Date {
+	switch v := value.(type) {
+	case Date:
+		return v
+	case time.Time:
+		if !v.IsZero() {
+			return DateOf(v)
+		}
+	}
+	return Date{}
+}
+
+// checkDate checks the date against the given limits and adds errors
+// for violated limits.
+func (w *WidgetBase) checkDate(d, min, max Date, minError,
+	maxError string) bool {
+	valid := true
+	if !min.IsZero() && d.Before(min) {
+		s, _ := w.form.formatDate(min)
+		w.addError(minError, "htmlwidgets.min-date",
+			map[string]interface{}{"min": s})
+		valid = false
+	}
+	if !max.IsZero() && d.After(max) {
+		s, _ := w.form.formatDate(max)
+		w.addError(maxError, "htmlwidgets.max-date",
+			map[string]interface{}{"max": s})
+		valid = false
+	}
+	return valid
+}
+
+// DateWidget is a widget for dates rendered as HTML5 date input.
+//
+// It fills Date and time.Time fields. Times are set to midnight UTC of
+// the date and rendered with the date in their own location, so dates
+// don't shift between timezones.
+//
+// If the form has a locale with a LocaleFormat, dates are also parsed
+// and rendered with its DateLayouts. If no value is submitted, the
+// zero value is filled in and the validators are run with nil.
+type DateWidget struct {
+	WidgetBase
+	// Min and Max are the earliest and latest accepted dates. Zero
+	// dates mean no limit.
+	Min, Max Date
+	// ParseError, MinError and MaxError are the messages for values
+	// that can't be parsed or violate the limits. If empty, default
+	// messages are used.
+	ParseError, MinError, MaxError string
+	// invalid is the last submitted value if it could not be parsed.
+	invalid *string
+}
+
+func (w *DateWidget) GetRenderData() WidgetRenderData {
+	value, err := w.form.getNestedField(w.Id)
+	if err != nil {
+		panic(err)
+	}
+	s, localized := w.form.formatDate(dateOf(fieldInterface(value)))
+	if w.invalid != nil {
+		s = *w.invalid
+	}
+	return WidgetRenderData{
+		WidgetBase: w.WidgetBase,
+		Template:   "date",
+		Data: TemporalRenderData{
+			Value: s,
+			Min:   w.Min.String(),
+			Max:   w.Max.String(),
+		},
+		Localized: localized}
+}
+
+func (w *DateWidget) Fill(values url.Values) bool {
+	w.Errors = nil
+	w.invalid = nil
+	raw := strings.TrimSpace(values.Get(w.Id))
+	if raw == "" {
+		w.form.clearField(w.Id, dateType)
+		return w.validate(nil)
+	}
+	date, err := w.form.parseDate(raw)
+	if err != nil {
+		w.invalid = &raw
+		w.addError(w.ParseError, "htmlwidgets.date", nil)
+		return false
+	}
+	if w.form.targetType(w.Id, dateType) == timeType {
+		w.form.setField(w.Id, date.Time(time.UTC))
+	} else {
+		w.form.setField(w.Id, date)
+	}
+	valid := w.checkDate(date, w.Min, w.Max, w.MinError, w.MaxError)
+	return w.validate(date) && valid
+}
+
+func (w *DateWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	if acceptsType(t, timeType) {
+		return nil
+	}
+	return verifyAssignable(id, t, dateType)
+}
+
+// TimeOfDayWidget is a widget for TimeOfDay fields rendered as HTML5
+// time input.
+//
+// If the form has a locale with a LocaleFormat, times are also parsed
+// and rendered with its TimeLayouts. If no value is submitted, the
+// zero value is filled in and the validators are run with nil. Use a
+// *TimeOfDay to tell midnight and missing values apart.
+type TimeOfDayWidget struct {
+	WidgetBase
+	// Min and Max are the earliest and latest accepted times. Zero
+	// times mean no limit.
+	Min, Max TimeOfDay
+	// ParseError, MinError and MaxError are the messages for values
+	// that can't be parsed or violate the limits. If empty, default
+	// messages are used.
+	ParseError, MinError, MaxError string
+	// invalid is the last submitted value if it could not be parsed.
+	invalid *string
+}
+
+func (w *TimeOfDayWidget) GetRenderData() WidgetRenderData {
+	value, err := w.form.getNestedField(w.Id)
+	if err != nil {
+		panic(err)
+	}
+	t, ok := fieldInterface(value).(TimeOfDay)
+	s, localized := w.form.formatTimeOfDay(t)
+	if !ok {
+		s = ""
+	}
+	if w.invalid != nil {
+		s = *w.invalid
+	}
+	data := TemporalRenderData{Value: s}
+	if w.Min != (TimeOfDay{}) {
+		data.Min = w.Min.String()
+	}
+	if w.Max != (TimeOfDay{}) {
+		data.Max = w.Max.String()
+	}
+	return WidgetRenderData{
+		WidgetBase: w.WidgetBase,
+		Template:   "timeofday",
+		Data:       data,
+		Localized:  localized}
+}
+
+func (w *TimeOfDayWidget) Fill(values url.Values) bool {
+	w.Errors = nil
+	w.invalid = nil
+	raw := strings.TrimSpace(values.Get(w.Id))
+	if raw == "" {
+		w.form.clearField(w.Id, timeOfDayType)
+		return w.validate(nil)
+	}
+	t, err := w.form.parseTimeOfDay(raw)
+	if err != nil {
+		w.invalid = &raw
+		w.addError(w.ParseError, "htmlwidgets.time-of-day", nil)
+		return false
+	}
+	w.form.setField(w.Id, t)
+	valid := true
+	if w.Min != (TimeOfDay{}) && t.Before(w.Min) {
+		s, _ := w.form.formatTimeOfDay(w.Min)
+		w.addError(w.MinError, "htmlwidgets.min-time",
+			map[string]interface{}{"min": s})
+		valid = false
+	}
+	if w.Max != (TimeOfDay{}) && t.After(w.Max) {
+		s, _ := w.form.formatTimeOfDay(w.Max)
+		w.addError(w.MaxError, "htmlwidgets.max-time",
+			map[string]interface{}{"max": s})
+		valid = false
+	}
+	return w.validate(t) && valid
+}
+
+func (w *TimeOfDayWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	return verifyAssignable(id, t, timeOfDayType)
+}
+
+// DateRangeRenderData is the Data of the render data of a
+// DateRangeWidget.
+type DateRangeRenderData struct {
+	// Start and End are the formatted dates or the submitted values if
+	// they could not be parsed.
+	Start, End string
+	// Min and Max are the limits of the widget in the format of HTML5
+	// date inputs. Empty values mean no limit.
+	Min, Max string
+}
+
+// DateRangeWidget is a widget for DateRange fields rendered as two
+// HTML5 date inputs, which are submitted as "<id>.Start" and
+// "<id>.End".
+//
+// Both dates are optional, empty values give open ends. Min and Max
+// apply to both dates, and the end must not be before the start.
+// Dates are parsed and rendered like by DateWidget.
+type DateRangeWidget struct {
+	WidgetBase
+	// Min and Max are the earliest and latest accepted dates. Zero
+	// dates mean no limit.
+	Min, Max Date
+	// ParseError, MinError, MaxError and OrderError are the messages
+	// for values that can't be parsed, violate the limits or end before
+	// they start. If empty, default messages are used.
+	ParseError, MinError, MaxError, OrderError string
+	// invalidStart and invalidEnd are the last submitted values if they
+	// could not be parsed.
+	invalidStart, invalidEnd *string
+}
+
+func (w *DateRangeWidget) GetRenderData() WidgetRenderData {
+	value, err := w.form.getNestedField(w.Id)
+	if err != nil {
+		panic(err)
+	}
+	r, _ := fieldInterface(value).(DateRange)
+	start, localized := w.form.formatDate(r.Start)
+	end, _ := w.form.formatDate(r.End)
+	if w.invalidStart != nil {
+		start = *w.invalidStart
+	}
+	if w.invalidEnd != nil {
+		end = *w.invalidEnd
+	}
+	return WidgetRenderData{
+		WidgetBase: w.WidgetBase,
+		Template:   "daterange",
+		Data: DateRangeRenderData{
+			Start: start,
+			End:   end,
+			Min:   w.Min.String(),
+			Max:   w.Max.String(),
+		},
+		Localized: localized}
+}
+
+func (w *DateRangeWidget) Fill(values url.Values) bool {
+	w.Errors = nil
+	w.invalidStart, w.invalidEnd = nil, nil
+	var r DateRange
+	valid := true
+	parse := func(suffix string, date *Date) *string {
+		raw := strings.TrimSpace(values.Get(w.Id + suffix))
+		if raw == "" {
+			return nil
+		}
+		d, err := w.form.parseDate(raw)
+		if err != nil {
+			valid = false
+			return &raw
+		}
+		*date = d
+		return nil
+	}
+	w.invalidStart = parse(".Start", &r.Start)
+	w.invalidEnd = parse(".End", &r.End)
+	if !valid {
+		w.addError(w.ParseError, "htmlwidgets.date", nil)
+		return false
+	}
+	w.form.setField(w.Id, r)
+	for _, d := range []Date{r.Start, r.End} {
+		if !d.IsZero() && !w.checkDate(d, w.Min, w.Max, w.MinError,
+			w.MaxError) {
+			valid = false
+			break
+		}
+	}
+	if !r.Start.IsZero() && !r.End.IsZero() && r.End.Before(r.Start) {
+		w.addError(w.OrderError, "htmlwidgets.date-order", nil)
+		valid = false
+	}
+	return w.validate(r) && valid
+}
+
+func (w *DateRangeWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	return verifyAssignable(id, t, dateRangeType)
+}
+
+// DurationWidget is a widget for time.Duration fields.
+//
+// It accepts durations like "1h30m", "1h 30m", "1.5 hours",
+// "90 minutes", "2 Stunden", "1:30" (hours and minutes) or "1:30:15"
+// and plain numbers in the given Unit. Units are written as in
+// time.ParseDuration or as English or German words. Durations are
+// rendered like "1h 30m", zero durations as empty value.
+//
+// If no value is submitted, zero is filled in and the validators are
+// run with nil.
+type DurationWidget struct {
+	WidgetBase
+	// Min and Max limit the accepted durations. If Min is nil, negative
+	// durations are rejected. If Max is nil, there is no upper limit.
+	Min, Max *time.Duration
+	// Unit is the unit of plain numbers. If zero, numbers are minutes.
+	Unit time.Duration
+	// ParseError, MinError and MaxError are the messages for values
+	// that can't be parsed or violate the limits. If empty, default
+	// messages are used.
+	ParseError, MinError, MaxError string
+	// invalid is the last submitted value if it could not be parsed.
+	invalid *string
+}
+
+func (w *DurationWidget) GetRenderData() WidgetRenderData {
+	value, err := w.form.getNestedField(w.Id)
+	if err != nil {
+		panic(err)
+	}
+	d, _ := fieldInterface(value).(time.Duration)
+	data := TemporalRenderData{Value: formatDuration(d)}
+	if w.Min != nil {
+		data.Min = formatDurationLimit(*w.Min)
+	}
+	if w.Max != nil {
+		data.Max = formatDurationLimit(*w.Max)
+	}
+	if w.invalid != nil {
+		data.Value = *w.invalid
+	}
+	return WidgetRenderData{
+		WidgetBase: w.WidgetBase,
+		Template:   "duration",
+		Data:       data}
+}
+
+func (w *DurationWidget) Fill(values url.Values) bool {
+	w.Errors = nil
+	w.invalid = nil
+	raw := strings.TrimSpace(values.Get(w.Id))
+	if raw == "" {
+		w.form.clearField(w.Id, durationType)
+		return w.validate(nil)
+	}
+	unit := w.Unit
+	if unit == 0 {
+		unit = time.Minute
+	}
+	decimal := "."
+	if lf := w.form.localeFormat(); lf != nil {
+		decimal = lf.DecimalSeparator
+	}
+	d, err := parseDuration(raw, unit, decimal)
+	if err != nil {
+		w.invalid = &raw
+		w.addError(w.ParseError, "htmlwidgets.duration", nil)
+		return false
+	}
+	w.form.setField(w.Id, d)
+	valid := true
+	var min time.Duration
+	if w.Min != nil {
+		min = *w.Min
+	}
+	if d < min {
+		w.addError(w.MinError, "htmlwidgets.min-duration",
+			map[string]interface{}{"min": formatDurationLimit(min)})
+		valid = false
+	}
+	if w.Max != nil && d > *w.Max {
+		w.addError(w.MaxError, "htmlwidgets.max-duration",
+			map[string]interface{}{"max": formatDurationLimit(*w.Max)})
+		valid = false
+	}
+	return w.validate(d) && valid
+}
+
+func (w *DurationWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	return verifyAssignable(id, t, durationType)
+}
+
+// durationUnits maps the unit names accepted by DurationWidget to
+// their durations.
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "us": time.Microsecond, "µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second, "sec": time.Second, "secs": time.Second,
+	"second": time.Second, "seconds": time.Second,
+	"sekunde": time.Second, "sekunden": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute,
+	"minute": time.Minute, "minutes": time.Minute, "minuten": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour,
+	"hours": time.Hour, "std": time.Hour, "stunde": time.Hour,
+	"stunden": time.Hour,
+	"d":       24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"tag": 24 * time.Hour, "tage": 24 * time.Hour,
+}
+
+var (
+	durationClockRe = regexp.MustCompile(`^(\d+):(\d{2})(?::(\d{2}))?$`)
+	durationRe      = regexp.MustCompile(`^(?:\d+(?:\.\d*)?\s*[a-zµ]+\s*)+$`)
+	durationPartRe  = regexp.MustCompile(`(\d+(?:\.\d*)?)\s*([a-zµ]+)`)
+)
+
+// parseDuration parses a duration as accepted by DurationWidget. Plain
+// numbers are multiplied by unit, decimal is the decimal separator of
+// numbers.
+func parseDuration(s string, unit time.Duration, decimal string) (
+	time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	sign := time.Duration(1)
+	if strings.HasPrefix(s, "-") {
+		sign, s = -1, strings.TrimSpace(s[1:])
+	}
+	if decimal != "." {
+		s = strings.Replace(s, decimal, ".", -1)
+	}
+	// The duration is summed up as float to detect overflows.
+	var d float64
+	switch matches := durationClockRe.FindStringSubmatch(s); {
+	case matches != nil:
+		for i, unit := range []time.Duration{time.Hour, time.Minute,
+			time.Second} {
+			if matches[i+1] != "" {
+				n, _ := strconv.ParseFloat(matches[i+1], 64)
+				d += n * float64(unit)
+			}
+		}
+	case decimalRe.MatchString(s):
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, err
+		}
+		d = n * float64(unit)
+	case durationRe.MatchString(s):
+		for _, part := range durationPartRe.FindAllStringSubmatch(s, -1) {
+			unit, ok := durationUnits[part[2]]
+			if !ok {
+				return 0, fmt.Errorf("unknown unit %q", part[2])
+			}
+			n, err := strconv.ParseFloat(part[1], 64)
+			if err != nil {
+				return 0, err
+			}
+			d += n * float64(unit)
+		}
+	default:
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	if d >= math.MaxInt64 {
+		return 0, fmt.Errorf("duration %q out of range", s)
+	}
+	return sign * time.Duration(d), nil
+}
+
+// formatDurationLimit formats a limit of a DurationWidget like
+// formatDuration, but zero as "0s".
+func formatDurationLimit(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+	return formatDuration(d)
+}
+
+// formatDuration formats a duration like "1h 30m". Zero durations give
+// an empty string.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	if d%time.Second != 0 {
+		return d.String()
+	}
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	var parts []string
+	for _, unit := range []struct {
+		Name     string
+		Duration time.Duration
+	}{{"h", time.Hour}, {"m", time.Minute}, {"s", time.Second}} {
+		if n := d / unit.Duration; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", n, unit.Name))
+			d -= n * unit.Duration
+		}
+	}
+	return sign + strings.Join(parts, " ")
+}
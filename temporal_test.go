@@ -0,0 +1,297 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"bytes"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDate(t *testing.T) {
+	d, err := ParseDate("2026-10-16")
+	if err != nil || d != (Date{2026, time.October, 16}) {
+		t.Fatalf("Parsed %v, %v", d, err)
+	}
+	if d.String() != "2026-10-16" || (Date{}).String() != "" {
+		t.Errorf("Invalid strings %q, %q", d, Date{})
+	}
+	late := time.Date(2026, time.October, 16, 23, 30, 0, 0,
+		time.FixedZone("UTC-10", -10*3600))
+	if DateOf(late) != d {
+		t.Errorf("DateOf(%v) is %v", late, DateOf(late))
+	}
+	next := Date{2026, time.October, 17}
+	if !d.Before(next) || d.After(next) || !next.After(d) || d.Before(d) {
+		t.Errorf("Invalid order of %v and %v", d, next)
+	}
+	var parsed Date
+	if err := parsed.UnmarshalText([]byte("2026-10-17")); err != nil ||
+		parsed != next {
+		t.Errorf("Unmarshaled %v, %v", parsed, err)
+	}
+	if _, err := ParseDate("2026-02-30"); err == nil {
+		t.Errorf("Parsed invalid date")
+	}
+	tod, err := ParseTimeOfDay("09:05")
+	if err != nil || tod != (TimeOfDay{9, 5, 0}) || tod.String() != "09:05" {
+		t.Errorf("Parsed time of day %v, %v", tod, err)
+	}
+	if s := (TimeOfDay{23, 59, 30}).String(); s != "23:59:30" {
+		t.Errorf("Time of day with seconds is %q", s)
+	}
+	r := DateRange{Start: d}
+	if !r.Contains(next) || r.Contains(Date{2026, time.October, 15}) {
+		t.Errorf("Invalid Contains of %v", r)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		Input    string
+		Decimal  string
+		Expected time.Duration
+		Valid    bool
+	}{
+		{"1h30m", ".", 90 * time.Minute, true},
+		{"1h 30m", ".", 90 * time.Minute, true},
+		{"1.5 hours", ".", 90 * time.Minute, true},
+		{"1,5 Stunden", ",", 90 * time.Minute, true},
+		{"90 min", ".", 90 * time.Minute, true},
+		{"2 days", ".", 48 * time.Hour, true},
+		{"1:30", ".", 90 * time.Minute, true},
+		{"1:30:15", ".", 90*time.Minute + 15*time.Second, true},
+		{"45", ".", 45 * time.Minute, true},
+		{"-10s", ".", -10 * time.Second, true},
+		{"1 fortnight", ".", 0, false},
+		{"1:3", ".", 0, false},
+		{"h", ".", 0, false},
+		{"99999999999999 hours", ".", 0, false},
+		{"2562047h 48m", ".", 0, false},
+		{"9999999999999999999", ".", 0, false},
+	}
+	for i, test := range tests {
+		d, err := parseDuration(test.Input, time.Minute, test.Decimal)
+		if (err == nil) != test.Valid || d != test.Expected {
+			t.Errorf("Test %d: Parsed %q as %v, %v", i, test.Input, d, err)
+		}
+	}
+	formatTests := map[time.Duration]string{
+		0:                            "",
+		90 * time.Minute:             "1h 30m",
+		26*time.Hour + 5*time.Second: "26h 5s",
+		-45 * time.Second:            "-45s",
+		1500 * time.Millisecond:      "1.5s",
+	}
+	for d, expected := range formatTests {
+		if s := formatDuration(d); s != expected {
+			t.Errorf("Formatted %v as %q, expected %q", d, s, expected)
+		}
+	}
+}
+
+func TestTemporalWidgets(t *testing.T) {
+	data := struct {
+		Birthday Date
+		Deadline time.Time
+		Alarm    *TimeOfDay
+		Vacation DateRange
+		Timeout  time.Duration
+	}{}
+	form := NewForm(&data)
+	form.AddWidget(&DateWidget{Max: Date{2026, time.December, 31}},
+		"Birthday", "", "")
+	form.AddWidget(new(DateWidget), "Deadline", "", "")
+	form.AddWidget(&TimeOfDayWidget{Min: TimeOfDay{Hour: 6}}, "Alarm", "", "")
+	form.AddWidget(new(DateRangeWidget), "Vacation", "", "")
+	maxTimeout := 8 * time.Hour
+	form.AddWidget(&DurationWidget{Max: &maxTimeout}, "Timeout", "", "")
+	if err := form.Verify(); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !form.Fill(url.Values{
+		"Birthday":       []string{"2026-10-16"},
+		"Deadline":       []string{"2026-11-01"},
+		"Alarm":          []string{"06:30"},
+		"Vacation.Start": []string{"2026-12-20"},
+		"Vacation.End":   []string{""},
+		"Timeout":        []string{"1h 30m"},
+	}) {
+		t.Fatalf("Fill failed: %v", form.RenderData())
+	}
+	deadline := time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)
+	if data.Birthday != (Date{2026, time.October, 16}) ||
+		!data.Deadline.Equal(deadline) ||
+		data.Alarm == nil || *data.Alarm != (TimeOfDay{6, 30, 0}) ||
+		data.Vacation != (DateRange{Start: Date{2026, time.December, 20}}) ||
+		data.Timeout != 90*time.Minute {
+		t.Errorf("Filled data is %+v", data)
+	}
+	var buf bytes.Buffer
+	if err := NewRenderer().Render(&buf, form.RenderData()); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	for _, expected := range []string{
+		`<input type="date" id="Birthday" name="Birthday" value="2026-10-16" max="2026-12-31">`,
+		`<input type="date" id="Deadline" name="Deadline" value="2026-11-01">`,
+		`<input type="time" id="Alarm" name="Alarm" value="06:30" min="06:00">`,
+		`<input type="date" name="Vacation.Start" value="2026-12-20"> &ndash; <input type="date" name="Vacation.End" value="">`,
+		`<input type="text" id="Timeout" name="Timeout" value="1h 30m">`,
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Rendered form lacks %s:\n%s", expected, buf.String())
+		}
+	}
+
+	result := form.FillValues(url.Values{
+		"Birthday":       []string{"2027-01-01"},
+		"Deadline":       []string{"2026-13-01"},
+		"Alarm":          []string{"05:59"},
+		"Vacation.Start": []string{"2026-12-20"},
+		"Vacation.End":   []string{"2026-12-19"},
+		"Timeout":        []string{"9 hours"},
+	})
+	expected := map[string][]string{
+		"Birthday": []string{"Please enter a date not after 2026-12-31."},
+		"Deadline": []string{"Please enter a valid date."},
+		"Alarm":    []string{"Please enter a time not before 06:00."},
+		"Vacation": []string{"The end date must not be before the start date."},
+		"Timeout":  []string{"Please enter a duration of at most 8h."},
+	}
+	if result.Valid || !reflect.DeepEqual(result.Errors, expected) {
+		t.Errorf("Errors are %v, expected %v", result.Errors, expected)
+	}
+	if !data.Deadline.Equal(deadline) {
+		t.Errorf("Unparseable value changed deadline to %v", data.Deadline)
+	}
+	if rd := form.RenderData().Widgets[1]; rd.Data.(TemporalRenderData).Value !=
+		"2026-13-01" {
+		t.Errorf("Invalid value not rendered again: %v", rd)
+	}
+
+	result = form.FillValues(url.Values{"Timeout": []string{"-1h"}})
+	if !reflect.DeepEqual(result.Errors["Timeout"],
+		[]string{"Please enter a duration of at least 0s."}) {
+		t.Errorf("Negative duration gave errors %v", result.Errors)
+	}
+	minTimeout := -time.Hour
+	form.WidgetById("Timeout").(*DurationWidget).Min = &minTimeout
+	result = form.FillValues(url.Values{"Timeout": []string{"-1h"}})
+	if len(result.Errors["Timeout"]) > 0 {
+		t.Errorf("Negative duration above Min gave errors %v", result.Errors)
+	}
+
+	result = form.FillValues(url.Values{})
+	if !result.Valid || data.Alarm != nil || !data.Birthday.IsZero() ||
+		data.Timeout != 0 || !data.Deadline.IsZero() {
+		t.Errorf("Empty values filled %+v, %v", data, result)
+	}
+}
+
+func TestLocalizedTemporalWidgets(t *testing.T) {
+	data := struct {
+		Day      Date
+		Time     TimeOfDay
+		Vacation DateRange
+		Duration time.Duration
+	}{}
+	form := NewForm(&data)
+	form.Locale = "de"
+	form.LocalizeInputs = true
+	form.AddWidget(&DateWidget{Min: Date{2026, time.January, 1}}, "Day", "", "")
+	form.AddWidget(new(TimeOfDayWidget), "Time", "", "")
+	form.AddWidget(new(DateRangeWidget), "Vacation", "", "")
+	form.AddWidget(new(DurationWidget), "Duration", "", "")
+	if !form.Fill(url.Values{
+		"Day":            []string{"16.10.2026"},
+		"Time":           []string{"14:30"},
+		"Vacation.Start": []string{"1.12.2026"},
+		"Vacation.End":   []string{"24.12.2026"},
+		"Duration":       []string{"1,5 Std"},
+	}) {
+		t.Fatalf("Fill failed: %v", form.RenderData())
+	}
+	if data.Day != (Date{2026, time.October, 16}) ||
+		data.Time != (TimeOfDay{14, 30, 0}) ||
+		data.Vacation.End != (Date{2026, time.December, 24}) ||
+		data.Duration != 90*time.Minute {
+		t.Errorf("Filled data is %+v", data)
+	}
+	rd := form.RenderData()
+	if rd.Widgets[0].Data.(TemporalRenderData).Value != "16.10.2026" ||
+		rd.Widgets[1].Data.(TemporalRenderData).Value != "14:30" ||
+		rd.Widgets[2].Data.(DateRangeRenderData).Start != "01.12.2026" {
+		t.Errorf("Invalid render data %v", rd.Widgets)
+	}
+	for i, widget := range rd.Widgets[:3] {
+		if !widget.Localized {
+			t.Errorf("Widget %d is not localized", i)
+		}
+	}
+	result := form.FillValues(url.Values{"Day": []string{"31.12.2025"}})
+	expected := []string{
+		"Bitte geben Sie ein Datum nicht vor dem 01.01.2026 ein."}
+	if !reflect.DeepEqual(result.Errors["Day"], expected) {
+		t.Errorf("Errors are %v, expected %v", result.Errors, expected)
+	}
+}
+
+func TestTemporalStructForm(t *testing.T) {
+	data := struct {
+		Day      Date          `htmlwidgets:"min=2026-01-01,max=2026-12-31"`
+		Time     TimeOfDay     `htmlwidgets:"max=18:00"`
+		Vacation DateRange     `htmlwidgets:"required"`
+		Duration time.Duration `htmlwidgets:"min=15m"`
+		Due      time.Time     `htmlwidgets:"widget=date"`
+	}{}
+	form := NewFormFromStruct(&data)
+	minDuration := 15 * time.Minute
+	expected := []Widget{
+		&DateWidget{Min: Date{2026, time.January, 1},
+			Max: Date{2026, time.December, 31}},
+		&TimeOfDayWidget{Max: TimeOfDay{Hour: 18}},
+		new(DateRangeWidget),
+		&DurationWidget{Min: &minDuration},
+		new(DateWidget),
+	}
+	for i, widget := range form.Widgets {
+		if reflect.TypeOf(widget) != reflect.TypeOf(expected[i]) {
+			t.Errorf("Widget %d is a %T", i, widget)
+			continue
+		}
+		got := reflect.ValueOf(widget).Elem()
+		want := reflect.ValueOf(expected[i]).Elem()
+		if !reflect.DeepEqual(got.FieldByName("Min").Interface(),
+			want.FieldByName("Min").Interface()) ||
+			!reflect.DeepEqual(got.FieldByName("Max").Interface(),
+				want.FieldByName("Max").Interface()) {
+			t.Errorf("Widget %d has limits %v, %v", i,
+				got.FieldByName("Min"), got.FieldByName("Max"))
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Invalid min option didn't panic")
+		}
+	}()
+	NewFormFromStruct(&struct {
+		Day Date `htmlwidgets:"min=16.10.2026"`
+	}{})
+}
@@ -0,0 +1,417 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// MultipartWidget is implemented by widgets handling uploaded files.
+//
+// Form.FillRequest calls FillMultipart instead of Fill for these
+// widgets and the EncTypeAttr of the form's render data is set if a
+// form contains one of them.
+type MultipartWidget interface {
+	Widget
+	// FillMultipart fills the submitted values and files into the app
+	// struct and returns true if they are valid.
+	FillMultipart(values url.Values,
+		files map[string][]*multipart.FileHeader) bool
+}
+
+// Upload is an uploaded file read into memory.
+type Upload struct {
+	Filename string
+	// ContentType is the type sniffed from the file's content.
+	ContentType string
+	Size        int64
+	Data        []byte
+}
+
+// FileWidget is a file upload widget that can be used to render a
+// HTML file input.
+//
+// The uploaded files are only available if the form is filled with
+// FillRequest. They are filled into a field of one of these types:
+//
+//	*multipart.FileHeader    the first file
+//	[]*multipart.FileHeader  all files
+//	[]byte                   the content of the first file
+//	Upload, *Upload          the first file read into memory
+//	[]Upload                 all files read into memory
+//
+// Fields of string type are left untouched, so you have to process the
+// upload by yourself. If the type can't be determined, a
+// *multipart.FileHeader is filled in.
+//
+// The validators are run with the []*multipart.FileHeader of the
+// uploaded files or the []Upload restored from the Store. No files are
+// filled in if a validator fails. Use MaxFileSize, AllowedTypes and
+// MaxFiles to restrict uploads.
+//
+// If a Store is set, valid uploads are stashed in it and the render
+// data contains a token referring to them. If the form is submitted
+// again with the token but without new files, the stashed uploads are
+// filled in. Fields of type *multipart.FileHeader and
+// []*multipart.FileHeader can't be restored, use the other types
+// instead.
+//
+// The widget has to be added to the form directly, as StructWidgets and
+// ListWidgets don't pass uploaded files to their children. Form.Verify
+// and NewFormFromStruct report nested FileWidgets.
+//
+// If you add this widget to a Form, the EncTypeAttr ob the RenderData
+// will be set on rendering.
+type FileWidget struct {
+	WidgetBase
+	// Store stashes uploads between requests if set.
+	Store UploadStore
+	// stashed are the uploads stashed by the last fill and tokens the
+	// tokens of them.
+	stashed []Upload
+	tokens  []string
+}
+
+// FileRenderData is the Data of the render data of a FileWidget.
+type FileRenderData struct {
+	// Token refers to the stashed uploads and has to be submitted as
+	// value of the parameter "<id>--token". It's empty if nothing has
+	// been stashed.
+	Token string
+	// Filenames are the names of the stashed uploads.
+	Filenames []string
+}
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader{})
+	byteSliceType       = reflect.TypeOf([]byte{})
+	uploadType          = reflect.TypeOf(Upload{})
+	uploadSliceType     = reflect.TypeOf([]Upload{})
+)
+
+func (w *FileWidget) GetRenderData() WidgetRenderData {
+	data := FileRenderData{Token: strings.Join(w.tokens, ",")}
+	for _, upload := range w.stashed {
+		data.Filenames = append(data.Filenames, upload.Filename)
+	}
+	return WidgetRenderData{
+		WidgetBase: w.WidgetBase,
+		Template:   "file",
+		Data:       data,
+	}
+}
+
+// Fill fills the widget without any uploaded files.
+func (w *FileWidget) Fill(values url.Values) bool {
+	return w.FillMultipart(values, nil)
+}
+
+func (w *FileWidget) FillMultipart(values url.Values,
+	files map[string][]*multipart.FileHeader) bool {
+	w.Errors = nil
+	w.stashed, w.tokens = nil, nil
+	headers := files[w.Id]
+	var uploads []Upload
+	var validated interface{} = headers
+	if len(headers) == 0 && w.Store != nil {
+		uploads = w.restore(values.Get(w.Id + "--token"))
+		if uploads != nil {
+			validated = uploads
+		}
+	}
+	if !w.validate(validated) {
+		headers, uploads = nil, nil
+		w.stashed, w.tokens = nil, nil
+	}
+	target := fileHeaderType
+	if field, err := w.form.getNestedField(w.Id); err == nil &&
+		field.IsValid() {
+		target = field.Type()
+	}
+	if target.Kind() == reflect.String {
+		return len(w.Errors) == 0
+	}
+	var value reflect.Value
+	var err error
+	if target == fileHeaderType || target == fileHeaderSliceType {
+		value = bindHeaders(headers, target)
+	} else {
+		if len(headers) > 0 {
+			uploads, err = readUploads(headers)
+		}
+		if err == nil && len(headers) > 0 && w.Store != nil {
+			err = w.stash(uploads)
+		}
+		value = bindUploads(w.Id, uploads, target)
+	}
+	if err != nil {
+		w.Errors = append(w.Errors, w.errorMessage(err))
+		value = reflect.Zero(target)
+	}
+	w.form.setField(w.Id, value.Interface())
+	return len(w.Errors) == 0
+}
+
+func (w *FileWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	switch t {
+	case fileHeaderType, fileHeaderSliceType, byteSliceType, uploadType,
+		reflect.PtrTo(uploadType), uploadSliceType:
+		return nil
+	}
+	if t.Kind() == reflect.String {
+		return nil
+	}
+	return FieldErrors{fieldError(id, ErrTypeMismatch,
+		"FileWidget can't fill uploads into %v", t)}
+}
+
+// restore returns the uploads stashed with the given comma separated
+// tokens. Unknown tokens are ignored.
+func (w *FileWidget) restore(token string) []Upload {
+	if token == "" {
+		return nil
+	}
+	var uploads []Upload
+	for _, t := range strings.Split(token, ",") {
+		upload, err := w.Store.Get(t)
+		if err != nil {
+			continue
+		}
+		uploads = append(uploads, upload)
+		w.stashed = append(w.stashed, upload)
+		w.tokens = append(w.tokens, t)
+	}
+	return uploads
+}
+
+// stash puts the given uploads into the Store.
+func (w *FileWidget) stash(uploads []Upload) error {
+	for _, upload := range uploads {
+		token, err := w.Store.Put(upload)
+		if err != nil {
+			return newMessage("", "htmlwidgets.upload-store", nil)
+		}
+		w.stashed = append(w.stashed, upload)
+		w.tokens = append(w.tokens, token)
+	}
+	return nil
+}
+
+// bindHeaders converts the uploaded files to a *multipart.FileHeader
+// or []*multipart.FileHeader.
+func bindHeaders(headers []*multipart.FileHeader, t reflect.Type) reflect.Value {
+	if t == fileHeaderSliceType {
+		return reflect.ValueOf(headers)
+	}
+	if len(headers) == 0 {
+		return reflect.Zero(t)
+	}
+	return reflect.ValueOf(headers[0])
+}
+
+// bindUploads converts the uploads of the widget with the given id to a
+// value of type t.
+//
+// It panics with a FieldError if t is not supported by FileWidget.
+func bindUploads(id string, uploads []Upload, t reflect.Type) reflect.Value {
+	switch t {
+	case uploadSliceType:
+		return reflect.ValueOf(uploads)
+	case byteSliceType, uploadType, reflect.PtrTo(uploadType):
+		if len(uploads) == 0 {
+			return reflect.Zero(t)
+		}
+		switch t {
+		case byteSliceType:
+			return reflect.ValueOf(uploads[0].Data)
+		case uploadType:
+			return reflect.ValueOf(uploads[0])
+		}
+		return reflect.ValueOf(&uploads[0])
+	}
+	panic(fieldError(id, ErrTypeMismatch, "FileWidget can't fill uploads into %v",
+		t))
+}
+
+// readUploads reads the given uploaded files into memory.
+func readUploads(headers []*multipart.FileHeader) ([]Upload, error) {
+	var uploads []Upload
+	for _, header := range headers {
+		upload, err := readUpload(header)
+		if err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, upload)
+	}
+	return uploads, nil
+}
+
+// readUpload reads the given uploaded file into memory.
+func readUpload(header *multipart.FileHeader) (Upload, error) {
+	file, err := header.Open()
+	if err != nil {
+		return Upload{}, newMessage("", "htmlwidgets.upload-read", nil)
+	}
+	defer file.Close()
+	data, err := ioutil.ReadAll(file)
+	if err != nil {
+		return Upload{}, newMessage("", "htmlwidgets.upload-read", nil)
+	}
+	return Upload{
+		Filename:    header.Filename,
+		ContentType: http.DetectContentType(data),
+		Size:        int64(len(data)),
+		Data:        data,
+	}, nil
+}
+
+// uploadedFile describes an uploaded file checked by the file
+// validators.
+type uploadedFile struct {
+	size int64
+	// mediaType returns the media type detected from the content
+	// without parameters.
+	mediaType func() (string, error)
+}
+
+// sniffContentType returns the media type of the given file detected
+// by http.DetectContentType, without parameters.
+func sniffContentType(header *multipart.FileHeader) (string, error) {
+	file, err := header.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	mediaType, _, err := mime.ParseMediaType(
+		http.DetectContentType(buf[:n]))
+	return mediaType, err
+}
+
+// uploadedFiles returns the uploaded files of a value given to a file
+// validator, which may be a (slice of) *multipart.FileHeader or Upload.
+func uploadedFiles(value interface{}) []uploadedFile {
+	var files []uploadedFile
+	addHeader := func(header *multipart.FileHeader) {
+		files = append(files, uploadedFile{header.Size,
+			func() (string, error) { return sniffContentType(header) }})
+	}
+	addUpload := func(upload Upload) {
+		files = append(files, uploadedFile{upload.Size,
+			func() (string, error) {
+				mediaType, _, err := mime.ParseMediaType(upload.ContentType)
+				return mediaType, err
+			}})
+	}
+	switch v := value.(type) {
+	case []*multipart.FileHeader:
+		for _, header := range v {
+			addHeader(header)
+		}
+	case *multipart.FileHeader:
+		if v != nil {
+			addHeader(v)
+		}
+	case []Upload:
+		for _, upload := range v {
+			addUpload(upload)
+		}
+	case Upload:
+		addUpload(v)
+	case *Upload:
+		if v != nil {
+			addUpload(*v)
+		}
+	}
+	return files
+}
+
+// MaxFileSize returns a validator that fails if an uploaded file is
+// larger than max bytes.
+//
+// If message is empty, a default message will be used.
+func MaxFileSize(max int64, message string) Validator {
+	err := newMessage(message, "htmlwidgets.file-size",
+		map[string]interface{}{"max": max})
+	return ValidatorFunc(func(value interface{}) error {
+		for _, file := range uploadedFiles(value) {
+			if file.size > max {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AllowedTypes returns a validator that fails if the type of an
+// uploaded file is not one of the given media types. Types like
+// "image/*" match all subtypes.
+//
+// The type is detected from the content of the file, the type declared
+// by the client is ignored. If message is empty, a default message
+// will be used.
+func AllowedTypes(types []string, message string) Validator {
+	invalid := newMessage(message, "htmlwidgets.file-type", nil)
+	return ValidatorFunc(func(value interface{}) error {
+		for _, file := range uploadedFiles(value) {
+			mediaType, err := file.mediaType()
+			if err != nil || !matchMediaType(types, mediaType) {
+				return invalid
+			}
+		}
+		return nil
+	})
+}
+
+// matchMediaType returns true if mediaType matches one of the given
+// types.
+func matchMediaType(types []string, mediaType string) bool {
+	for _, t := range types {
+		if t == mediaType || strings.HasSuffix(t, "/*") &&
+			strings.HasPrefix(mediaType, t[:len(t)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxFiles returns a validator that fails if more than max files are
+// uploaded.
+//
+// If message is empty, a default message will be used.
+func MaxFiles(max int, message string) Validator {
+	err := newMessage(message, "htmlwidgets.max-files",
+		map[string]interface{}{"max": max})
+	return ValidatorFunc(func(value interface{}) error {
+		if len(uploadedFiles(value)) > max {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,216 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+var (
+	testPNG  = []byte("\x89PNG\x0D\x0A\x1A\x0Adata")
+	testText = []byte("Hello World")
+)
+
+// testUploadFile is a file submitted by newUploadRequest.
+type testUploadFile struct {
+	Field, Filename string
+	Data            []byte
+}
+
+// newUploadRequest creates a multipart POST request with the given
+// values and files.
+func newUploadRequest(t *testing.T, values map[string]string,
+	files ...testUploadFile) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for key, value := range values {
+		writer.WriteField(key, value)
+	}
+	for _, file := range files {
+		part, err := writer.CreateFormFile(file.Field, file.Filename)
+		if err != nil {
+			t.Fatalf("Can't create form file: %v", err)
+		}
+		part.Write(file.Data)
+	}
+	writer.Close()
+	req, err := http.NewRequest("POST", "/upload", &body)
+	if err != nil {
+		t.Fatalf("Can't create request: %v", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+type TestUploadData struct {
+	Name    string
+	Header  *multipart.FileHeader
+	Headers []*multipart.FileHeader
+	Bytes   []byte
+	Upload  Upload
+	Pointer *Upload
+	Uploads []Upload
+	Path    string
+}
+
+func TestFileWidgetUpload(t *testing.T) {
+	data := TestUploadData{Path: "unchanged"}
+	form := NewForm(&data)
+	form.AddWidget(new(TextWidget), "Name", "", "")
+	for _, id := range []string{"Header", "Headers", "Bytes", "Upload",
+		"Pointer", "Uploads", "Path"} {
+		form.AddWidget(new(FileWidget), id, "", "")
+	}
+	req := newUploadRequest(t, map[string]string{"Name": "Foo"},
+		testUploadFile{"Header", "a.png", testPNG},
+		testUploadFile{"Headers", "a.png", testPNG},
+		testUploadFile{"Headers", "b.txt", testText},
+		testUploadFile{"Bytes", "b.txt", testText},
+		testUploadFile{"Upload", "a.png", testPNG},
+		testUploadFile{"Pointer", "b.txt", testText},
+		testUploadFile{"Uploads", "a.png", testPNG},
+		testUploadFile{"Uploads", "b.txt", testText},
+		testUploadFile{"Path", "b.txt", testText})
+	if result, err := form.FillRequest(req); err != nil || !result.Valid {
+		t.Fatalf("Form should be valid: %v %v", result, err)
+	}
+	png := Upload{Filename: "a.png", ContentType: "image/png",
+		Size: int64(len(testPNG)), Data: testPNG}
+	text := Upload{Filename: "b.txt", ContentType: "text/plain; charset=utf-8",
+		Size: int64(len(testText)), Data: testText}
+	if data.Name != "Foo" {
+		t.Errorf("Name is %q, expected %q", data.Name, "Foo")
+	}
+	if data.Header == nil || data.Header.Filename != "a.png" {
+		t.Errorf("Header is %v, expected a.png", data.Header)
+	}
+	if len(data.Headers) != 2 || data.Headers[1].Filename != "b.txt" {
+		t.Errorf("Headers are %v, expected a.png and b.txt", data.Headers)
+	}
+	if !bytes.Equal(data.Bytes, testText) {
+		t.Errorf("Bytes are %q, expected %q", data.Bytes, testText)
+	}
+	if !reflect.DeepEqual(data.Upload, png) {
+		t.Errorf("Upload is %#v, expected %#v", data.Upload, png)
+	}
+	if data.Pointer == nil || !reflect.DeepEqual(*data.Pointer, text) {
+		t.Errorf("Pointer is %#v, expected %#v", data.Pointer, text)
+	}
+	if !reflect.DeepEqual(data.Uploads, []Upload{png, text}) {
+		t.Errorf("Uploads are %#v, expected %#v", data.Uploads,
+			[]Upload{png, text})
+	}
+	if data.Path != "unchanged" {
+		t.Errorf("String field should be left untouched, is %q", data.Path)
+	}
+	if form.RenderData().EncTypeAttr == "" {
+		t.Errorf("EncTypeAttr should be set for forms with file widgets")
+	}
+
+	req = newUploadRequest(t, map[string]string{"Name": "Bar"})
+	if result, _ := form.FillRequest(req); !result.Valid {
+		t.Fatalf("Form without uploads should be valid")
+	}
+	if data.Header != nil || data.Headers != nil || data.Bytes != nil ||
+		data.Pointer != nil || data.Uploads != nil || data.Upload.Size != 0 {
+		t.Errorf("Missing uploads should reset the fields: %#v", data)
+	}
+
+	req, _ = http.NewRequest("GET", "/upload?Name=Baz", nil)
+	if result, _ := form.FillRequest(req); !result.Valid || data.Name != "Baz" {
+		t.Errorf("Filling a request without multipart body failed")
+	}
+}
+
+func TestFileValidators(t *testing.T) {
+	tests := []struct {
+		Validator Validator
+		Files     []testUploadFile
+		Valid     bool
+	}{
+		{MaxFileSize(11, ""), []testUploadFile{{"Id", "b.txt", testText}}, true},
+		{MaxFileSize(10, ""), []testUploadFile{{"Id", "b.txt", testText}}, false},
+		{AllowedTypes([]string{"image/*"}, ""),
+			[]testUploadFile{{"Id", "a.png", testPNG}}, true},
+		{AllowedTypes([]string{"image/png"}, ""),
+			[]testUploadFile{{"Id", "a.png", testPNG}}, true},
+		{AllowedTypes([]string{"image/*"}, ""),
+			[]testUploadFile{{"Id", "b.png", testText}}, false},
+		{AllowedTypes([]string{"text/plain"}, ""),
+			[]testUploadFile{{"Id", "b.txt", testText}}, true},
+		{MaxFiles(1, ""), []testUploadFile{{"Id", "a.png", testPNG}}, true},
+		{MaxFiles(1, ""), []testUploadFile{{"Id", "a.png", testPNG},
+			{"Id", "b.txt", testText}}, false},
+		{Required(""), nil, false},
+	}
+	for i, test := range tests {
+		data := struct{ Id []*multipart.FileHeader }{}
+		form := NewForm(&data)
+		widget := new(FileWidget)
+		widget.Validators = []Validator{test.Validator}
+		form.AddWidget(widget, "Id", "", "")
+		result, _ := form.FillRequest(newUploadRequest(t, nil, test.Files...))
+		valid := result.Valid
+		if valid != test.Valid {
+			t.Errorf("Test %d: valid is %v, expected %v", i, valid, test.Valid)
+		}
+		if !valid && data.Id != nil {
+			t.Errorf("Test %d: invalid uploads should not be filled in", i)
+		}
+	}
+}
+
+func TestFileWidgetStore(t *testing.T) {
+	data := struct {
+		Name   string
+		Upload *Upload
+	}{}
+	form := NewForm(&data)
+	name := new(TextWidget)
+	name.Validators = []Validator{Required("")}
+	form.AddWidget(name, "Name", "", "")
+	form.AddWidget(&FileWidget{Store: NewMemoryUploadStore()}, "Upload", "",
+		"")
+	req := newUploadRequest(t, nil, testUploadFile{"Upload", "a.png", testPNG})
+	if result, _ := form.FillRequest(req); result.Valid {
+		t.Fatalf("Form without name should be invalid")
+	}
+	rd := form.RenderData().Widgets[1].Data.(FileRenderData)
+	if rd.Token == "" || !reflect.DeepEqual(rd.Filenames, []string{"a.png"}) {
+		t.Fatalf("Render data should contain the stashed upload: %#v", rd)
+	}
+
+	data.Upload = nil
+	req = newUploadRequest(t, map[string]string{"Name": "Foo",
+		"Upload--token": rd.Token})
+	if result, _ := form.FillRequest(req); !result.Valid {
+		t.Fatalf("Form with stashed upload should be valid")
+	}
+	if data.Upload == nil || !bytes.Equal(data.Upload.Data, testPNG) {
+		t.Errorf("Stashed upload should be filled in, got %#v", data.Upload)
+	}
+
+	req = newUploadRequest(t, map[string]string{"Name": "Foo",
+		"Upload--token": "unknown"})
+	if result, _ := form.FillRequest(req); !result.Valid || data.Upload != nil {
+		t.Errorf("Unknown tokens should be ignored, got %#v", data.Upload)
+	}
+}
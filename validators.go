@@ -0,0 +1,317 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Validator validates the value a widget filled into the app struct.
+//
+// Validators are assigned to WidgetBase.Validators. The message of each
+// error returned by a validator is translated and added to the
+// widget's errors. Return a *Message to translate it with parameters.
+// The built-in validators return Messages with the given message (or
+// the key of the default message if it's empty) as key, so custom
+// messages may be translation keys and contain parameters like "{min}"
+// as well.
+//
+// The built-in validators except Required accept empty values, so
+// they can be used for optional fields. Combine them with Required if
+// a value must be given.
+type Validator interface {
+	// Validate returns an error if the given value is invalid.
+	Validate(value interface{}) error
+}
+
+// ValidatorFunc is a function implementing the Validator interface.
+type ValidatorFunc func(value interface{}) error
+
+// Validate calls f(value).
+func (f ValidatorFunc) Validate(value interface{}) error {
+	return f(value)
+}
+
+// Required returns a validator that fails for empty values, i.e. nil,
+// false, zero structs and empty strings, slices and maps. Numbers are
+// never empty, as widgets validate nil if no number has been entered.
+//
+// If message is empty, a default message will be used.
+func Required(message string) Validator {
+	err := newMessage(message, "htmlwidgets.required", nil)
+	return ValidatorFunc(func(value interface{}) error {
+		if isEmpty(value) {
+			return err
+		}
+		return nil
+	})
+}
+
+// MinLength returns a validator that fails if a string has less than
+// min characters or a slice or map has less than min elements. Empty
+// values are accepted, so optional fields may be left empty. Combine it
+// with Required to reject them.
+//
+// If message is empty, a default message will be used.
+func MinLength(min int, message string) Validator {
+	err := newMessage(message, "htmlwidgets.min-length",
+		map[string]interface{}{"min": min})
+	return ValidatorFunc(func(value interface{}) error {
+		if !isEmpty(value) && length(value) < min {
+			return err
+		}
+		return nil
+	})
+}
+
+// MaxLength returns a validator that fails if a string has more than
+// max characters or a slice or map has more than max elements. Like
+// MinLength, it accepts empty values.
+//
+// If message is empty, a default message will be used.
+func MaxLength(max int, message string) Validator {
+	err := newMessage(message, "htmlwidgets.max-length",
+		map[string]interface{}{"max": max})
+	return ValidatorFunc(func(value interface{}) error {
+		if !isEmpty(value) && length(value) > max {
+			return err
+		}
+		return nil
+	})
+}
+
+// Regexp returns a validator that fails if a string does not match the
+// given regular expression.
+//
+// It panics if the expression can't be compiled. If message is empty,
+// a default message will be used.
+func Regexp(expr string, message string) Validator {
+	re := regexp.MustCompile(expr)
+	err := newMessage(message, "htmlwidgets.format", nil)
+	return ValidatorFunc(func(value interface{}) error {
+		if s := toString(value); s != "" && !re.MatchString(s) {
+			return err
+		}
+		return nil
+	})
+}
+
+// Range returns a validator that fails if a number is less than min or
+// greater than max. Use math.Inf to leave one side open.
+//
+// Strings, like those filled by NumberWidgets into string fields, are
+// compared as decimal numbers. Empty strings are accepted, strings
+// which aren't numbers fail.
+//
+// If message is empty, a default message will be used.
+func Range(min, max float64, message string) Validator {
+	key := "htmlwidgets.range"
+	switch {
+	case math.IsInf(max, 1):
+		key = "htmlwidgets.range-min"
+	case math.IsInf(min, -1):
+		key = "htmlwidgets.range-max"
+	}
+	err := newMessage(message, key,
+		map[string]interface{}{"min": min, "max": max})
+	return ValidatorFunc(func(value interface{}) error {
+		if value == nil {
+			return nil
+		}
+		if v := reflect.ValueOf(value); v.Kind() == reflect.String {
+			if !decimalInRange(v.String(), min, max) {
+				return err
+			}
+			return nil
+		}
+		if v := toFloat(value); v < min || v > max {
+			return err
+		}
+		return nil
+	})
+}
+
+// Email returns a validator that fails if a string is not a plain
+// email address like "foo@example.com".
+//
+// If message is empty, a default message will be used.
+func Email(message string) Validator {
+	invalid := newMessage(message, "htmlwidgets.email", nil)
+	return ValidatorFunc(func(value interface{}) error {
+		s := toString(value)
+		if s == "" {
+			return nil
+		}
+		if address, err := mail.ParseAddress(s); err != nil ||
+			address.Address != s {
+			return invalid
+		}
+		return nil
+	})
+}
+
+// URL returns a validator that fails if a string is not an absolute
+// URL with scheme and host.
+//
+// If message is empty, a default message will be used.
+func URL(message string) Validator {
+	invalid := newMessage(message, "htmlwidgets.url", nil)
+	return ValidatorFunc(func(value interface{}) error {
+		s := toString(value)
+		if s == "" {
+			return nil
+		}
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" ||
+			u.Host == "" {
+			return invalid
+		}
+		return nil
+	})
+}
+
+// OneOf returns a validator that fails if a value is not one of the
+// given values. Values are compared by their string representation.
+// For slices, each element has to be one of the given values.
+//
+// If message is empty, a default message will be used.
+func OneOf(values []string, message string) Validator {
+	err := newMessage(message, "htmlwidgets.invalid-option", nil)
+	allowed := make(map[string]bool, len(values))
+	for _, v := range values {
+		allowed[v] = true
+	}
+	return ValidatorFunc(func(value interface{}) error {
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice {
+			if s := toString(value); s != "" && !allowed[s] {
+				return err
+			}
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if !allowed[toString(v.Index(i).Interface())] {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// isEmpty returns true if the given value is nil, false, a zero struct
+// or an empty string, slice or map.
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64:
+		return false
+	}
+	return reflect.DeepEqual(value, reflect.Zero(v.Type()).Interface())
+}
+
+// length returns the number of characters of a string or the number of
+// elements of a slice or map.
+//
+// It panics for other types.
+func length(value interface{}) int {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String())
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len()
+	}
+	panic(fmt.Sprintf("htmlwidgets: Can't determine length of %T", value))
+}
+
+// toString returns the string representation of the given value.
+func toString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.String {
+		return v.String()
+	}
+	return fmt.Sprint(value)
+}
+
+// isRangeKind returns true if toFloat accepts values of the given kind.
+func isRangeKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32,
+		reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// decimalInRange returns true if the given string is empty or a decimal
+// number between min and max.
+func decimalInRange(s string, min, max float64) bool {
+	if s == "" {
+		return true
+	}
+	value, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return false
+	}
+	// SetFloat64 returns nil for infinite limits.
+	if limit := new(big.Rat).SetFloat64(min); limit != nil &&
+		value.Cmp(limit) < 0 {
+		return false
+	}
+	if limit := new(big.Rat).SetFloat64(max); limit != nil &&
+		value.Cmp(limit) > 0 {
+		return false
+	}
+	return true
+}
+
+// toFloat converts the given number to a float64.
+//
+// It panics if the value is not a number.
+func toFloat(value interface{}) float64 {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	panic(fmt.Sprintf("htmlwidgets: %T is not a number", value))
+}
@@ -0,0 +1,162 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidators(t *testing.T) {
+	tests := []struct {
+		Validator Validator
+		Value     interface{}
+		Valid     bool
+	}{
+		{Required(""), "", false},
+		{Required(""), "foo", true},
+		{Required(""), nil, false},
+		{Required(""), 0, true},
+		{Required(""), 1, true},
+		{Required(""), false, false},
+		{Required(""), []string{}, false},
+		{Required(""), []string{""}, true},
+		{Required(""), time.Time{}, false},
+		{Required(""), time.Now(), true},
+		{MinLength(3, ""), "", true},
+		{MinLength(3, ""), "fo", false},
+		{MinLength(3, ""), "foo", true},
+		{MinLength(3, ""), "äöü", true},
+		{MinLength(2, ""), []int{1}, false},
+		{MaxLength(3, ""), "foo", true},
+		{MaxLength(3, ""), "fooo", false},
+		{MaxLength(1, ""), []int{1, 2}, false},
+		{Regexp(`^\d+$`, ""), "", true},
+		{Regexp(`^\d+$`, ""), "12", true},
+		{Regexp(`^\d+$`, ""), "1a", false},
+		{Range(1, 10, ""), 0, false},
+		{Range(1, 10, ""), 1, true},
+		{Range(1, 10, ""), uint8(10), true},
+		{Range(1, 10, ""), 10.5, false},
+		{Range(math.Inf(-1), 0, ""), -100, true},
+		{Range(1, 10, ""), "", true},
+		{Range(1, 10, ""), "10.000000000000000001", false},
+		{Range(1, math.Inf(1), ""), "123456789012345678901234567890", true},
+		{Range(1, 10, ""), "abc", false},
+		{Email(""), "", true},
+		{Email(""), "foo@example.com", true},
+		{Email(""), "foo", false},
+		{Email(""), "Foo <foo@example.com>", false},
+		{URL(""), "", true},
+		{URL(""), "http://example.com/foo", true},
+		{URL(""), "/foo", false},
+		{URL(""), "example.com", false},
+		{OneOf([]string{"a", "b"}, ""), "", true},
+		{OneOf([]string{"a", "b"}, ""), "a", true},
+		{OneOf([]string{"a", "b"}, ""), "c", false},
+		{OneOf([]string{"1", "2"}, ""), 2, true},
+		{OneOf([]string{"a", "b"}, ""), []string{"a", "b"}, true},
+		{OneOf([]string{"a", "b"}, ""), []string{"a", "c"}, false},
+	}
+	for i, test := range tests {
+		err := test.Validator.Validate(test.Value)
+		if (err == nil) != test.Valid {
+			t.Errorf("Test %v: Validate(%#v) returned %v, valid should be %v",
+				i, test.Value, err, test.Valid)
+		}
+		if err != nil && err.Error() == "" {
+			t.Errorf("Test %v: Validate(%#v) returned an empty message", i,
+				test.Value)
+		}
+	}
+	if err := Required("Foo!").Validate(""); err == nil || err.Error() != "Foo!" {
+		t.Errorf("Required did not use the given message, returned %v", err)
+	}
+}
+
+type TestWidgetValidatorsData struct {
+	Name  string
+	Bio   string
+	Age   int
+	Alive bool
+}
+
+func TestWidgetValidators(t *testing.T) {
+	data := TestWidgetValidatorsData{}
+	form := NewForm(&data)
+	name := &TextWidget{MinLength: 5, ValidationError: ">=5"}
+	name.Validators = []Validator{Required("Required!"), MaxLength(2, "<=2")}
+	form.AddWidget(name, "Name", "", "")
+	bio := new(TextAreaWidget)
+	bio.Validators = []Validator{Regexp(`^\w*$`, "Word!")}
+	form.AddWidget(bio, "Bio", "", "")
+	age := new(IntegerWidget)
+	age.Validators = []Validator{Range(18, 99, "Adults only!"),
+		ValidatorFunc(func(value interface{}) error {
+			if value.(int)%2 != 0 {
+				return errors.New("Even only!")
+			}
+			return nil
+		})}
+	form.AddWidget(age, "Age", "", "")
+	alive := new(BoolWidget)
+	alive.Validators = []Validator{Required("Must be alive!")}
+	form.AddWidget(alive, "Alive", "", "")
+
+	vals := url.Values{
+		"Name": []string{"foo"},
+		"Bio":  []string{"foo bar"},
+		"Age":  []string{"17"},
+	}
+	if form.Fill(vals) {
+		t.Errorf("Fill returned true for invalid values")
+	}
+	expected := map[string][]string{
+		"Name":  []string{">=5", "<=2"},
+		"Bio":   []string{"Word!"},
+		"Age":   []string{"Adults only!", "Even only!"},
+		"Alive": []string{"Must be alive!"},
+	}
+	for _, widget := range form.Widgets {
+		if errors := widget.Base().Errors; !reflect.DeepEqual(errors,
+			expected[widget.Base().Id]) {
+			t.Errorf("Errors of %q are %v, expected %v", widget.Base().Id, errors,
+				expected[widget.Base().Id])
+		}
+	}
+
+	name.MinLength = 0
+	vals = url.Values{
+		"Name":  []string{"fo"},
+		"Bio":   []string{"foo"},
+		"Age":   []string{"18"},
+		"Alive": []string{"true"},
+	}
+	if !form.Fill(vals) {
+		t.Errorf("Fill returned false for valid values. Errors: %v",
+			form.RenderData().Widgets)
+	}
+	for _, widget := range form.Widgets {
+		if errors := widget.Base().Errors; len(errors) > 0 {
+			t.Errorf("Errors of %q are %v, expected none", widget.Base().Id, errors)
+		}
+	}
+}
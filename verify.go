@@ -0,0 +1,151 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// fieldVerifier is implemented by widgets which can check whether a
+// field of a given type can hold their values.
+type fieldVerifier interface {
+	// verifyField returns the problems of filling a field of type t. id
+	// is the id of the widget to report.
+	verifyField(id string, t reflect.Type) FieldErrors
+}
+
+// Verify checks the ids of the widgets against the type of the form
+// data, so mistakes can be found at startup instead of at fill or
+// render time. Fields must exist and be able to hold the values filled
+// in by the widgets. Child widgets of ListWidgets and StructWidgets are
+// checked as well; the item widgets of lists are reported with the id
+// of the first item. FileWidgets can't be children of these widgets, as
+// they only receive uploaded files if they are added to the form.
+//
+// Map entries can't be checked as they are only known at runtime, and
+// values below interface{} fields are skipped.
+//
+// It returns the problems of all widgets as FieldErrors or nil.
+func (f *Form) Verify() error {
+	dataType := reflect.TypeOf(f.data)
+	var errs FieldErrors
+	for _, widget := range f.Widgets {
+		id := widget.Base().Id
+		errs = append(errs, verifyWidget(widget, dataType, id, id)...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// verifyWidget checks the widget against the field with the given path
+// relative to type t. id is the full id of the widget to report.
+func verifyWidget(widget Widget, t reflect.Type, path, id string) FieldErrors {
+	fieldType, err := resolveFieldType(t, path, id)
+	if err != nil {
+		return FieldErrors{err}
+	}
+	if fieldType == nil || fieldType.Kind() == reflect.Interface {
+		return nil
+	}
+	if v, ok := widget.(fieldVerifier); ok {
+		return v.verifyField(id, fieldType)
+	}
+	return nil
+}
+
+// verifyChild checks the child widget of a StructWidget or ListWidget
+// like verifyWidget and reports MultipartWidgets, which are never
+// filled with files as children.
+func verifyChild(widget Widget, t reflect.Type, path, id string) FieldErrors {
+	if _, ok := widget.(MultipartWidget); ok {
+		return FieldErrors{fieldError(id, ErrTypeMismatch,
+			"%T can't be nested in StructWidgets or ListWidgets", widget)}
+	}
+	return verifyWidget(widget, t, path, id)
+}
+
+// resolveFieldType returns the type of the field with the given path
+// relative to type t like findNestedField resolves values. It returns
+// nil if the type can't be determined because of an interface{} value.
+func resolveFieldType(t reflect.Type, path, id string) (reflect.Type,
+	*FieldError) {
+	if path == "" {
+		return t, nil
+	}
+	for _, part := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Interface:
+			return nil, nil
+		case reflect.Struct:
+			field, ok := t.FieldByName(part)
+			if !ok || field.PkgPath != "" {
+				return nil, fieldError(id, ErrUnknownField,
+					"no struct field %q in %v", part, t)
+			}
+			t = field.Type
+		case reflect.Map:
+			if t.Key().Kind() != reflect.String {
+				return nil, fieldError(id, ErrTypeMismatch,
+					"map keys are of type %v", t.Key())
+			}
+			t = t.Elem()
+		case reflect.Slice:
+			if _, err := strconv.Atoi(part); err != nil {
+				return nil, fieldError(id, ErrUnknownField,
+					"expected index, got %q", part)
+			}
+			t = t.Elem()
+		default:
+			return nil, fieldError(id, ErrUnknownField, "can't find %q in %v",
+				part, t)
+		}
+	}
+	return t, nil
+}
+
+// acceptsType returns true if a field of type t can be set to values of
+// type valueType, directly or by a new pointer.
+func acceptsType(t, valueType reflect.Type) bool {
+	return valueType.AssignableTo(t) ||
+		t.Kind() == reflect.Ptr && valueType.AssignableTo(t.Elem())
+}
+
+// verifyAssignable checks that a field of type t can be set to values
+// of type valueType.
+func verifyAssignable(id string, t, valueType reflect.Type) FieldErrors {
+	if acceptsType(t, valueType) {
+		return nil
+	}
+	return FieldErrors{fieldError(id, ErrTypeMismatch,
+		"field of type %v can't hold %v", t, valueType)}
+}
+
+// indirectType returns the element type of pointer types and t
+// otherwise.
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,98 @@
+// This file is part of htmlwidgets.
+// Copyright 2014 Christian Neumann <cneumann@datenkarussell.de>
+
+// htmlwidgets is free software: you can redistribute it and/or modify it under
+// the terms of the GNU Lesser General Public License as published by the Free
+// Software Foundation, either version 3 of the License, or (at your option) any
+// later version.
+
+// htmlwidgets is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS
+// FOR A PARTICULAR PURPOSE. See the GNU Lesser General Public License for more
+// details.
+
+// You should have received a copy of the GNU Lesser General Public License
+// along with htmlwidgets. If not, see <http://www.gnu.org/licenses/>.
+
+package htmlwidgets
+
+import (
+	"mime/multipart"
+	"testing"
+	"time"
+)
+
+type TestVerifyData struct {
+	Name      string
+	Age       *int
+	Color     TestMultiSelectColor
+	Colors    []TestMultiSelectColor
+	Born      time.Time
+	Avatar    *multipart.FileHeader
+	Files     []*multipart.FileHeader
+	Address   TestAddress
+	Addresses []*TestAddress
+	Extra     map[string]interface{}
+	Counts    map[string]int
+	hidden    string
+}
+
+func TestFormVerify(t *testing.T) {
+	data := TestVerifyData{}
+	form := NewForm(&data)
+	form.AddWidget(new(TextWidget), "Name", "", "")
+	form.AddWidget(new(IntegerWidget), "Age", "", "")
+	form.AddWidget(new(SelectWidget), "Color", "", "")
+	form.AddWidget(new(MultiSelectWidget), "Colors", "", "")
+	form.AddWidget(new(TimeWidget), "Born", "", "")
+	form.AddWidget(new(FileWidget), "Avatar", "", "")
+	form.AddWidget(newTestAddressWidget(), "Address", "", "")
+	form.AddWidget(&ListWidget{InnerWidget: newTestAddressWidget()},
+		"Addresses", "", "")
+	form.AddWidget(new(StructWidget), "Extra.Anything", "", "")
+	form.AddWidget(new(NumberWidget), "Counts.foo", "", "")
+	if err := form.Verify(); err != nil {
+		t.Fatalf("Verify returned %v", err)
+	}
+
+	address := new(StructWidget)
+	address.AddWidget(new(TextWidget), "Stret", "", "")
+	address.AddWidget(new(BoolWidget), "City", "", "")
+	form.AddWidget(address, "Address", "", "")
+	form.AddWidget(&ListWidget{InnerWidget: new(TextWidget)}, "Addresses",
+		"", "")
+	form.AddWidget(new(TextWidget), "Nmae", "", "")
+	form.AddWidget(new(TextWidget), "hidden", "", "")
+	form.AddWidget(new(TextWidget), "Name.First", "", "")
+	form.AddWidget(new(TextWidget), "Colors.first", "", "")
+	form.AddWidget(new(TextWidget), "Age", "", "")
+	form.AddWidget(new(NumberWidget), "Born", "", "")
+	form.AddWidget(new(MultiSelectWidget), "Color", "", "")
+	form.AddWidget(new(SelectWidget), "Address", "", "")
+	form.AddWidget(new(FileWidget), "Counts", "", "")
+	form.AddWidget(&ListWidget{InnerWidget: new(TextWidget)}, "Name", "",
+		"")
+	form.AddWidget(new(TimeWidget), "Counts.foo", "", "")
+	form.AddWidget(&ListWidget{InnerWidget: new(FileWidget)}, "Files", "",
+		"")
+	checkFieldErrors(t, form.Verify(), map[string]error{
+		"Address.Stret": ErrUnknownField,
+		"Address.City":  ErrTypeMismatch,
+		"Addresses.0":   ErrTypeMismatch,
+		"Nmae":          ErrUnknownField,
+		"hidden":        ErrUnknownField,
+		"Name.First":    ErrUnknownField,
+		"Colors.first":  ErrUnknownField,
+		"Age":           ErrTypeMismatch,
+		"Born":          ErrTypeMismatch,
+		"Color":         ErrTypeMismatch,
+		"Address":       ErrTypeMismatch,
+		"Counts":        ErrTypeMismatch,
+		"Name":          ErrTypeMismatch,
+		"Counts.foo":    ErrTypeMismatch,
+		"Files.0":       ErrTypeMismatch,
+	})
+	if errs := form.Verify().(FieldErrors); len(errs) != 15 {
+		t.Errorf("Verify should report all problems, got %v", errs)
+	}
+}
@@ -19,8 +19,10 @@ package htmlwidgets
 import (
 	"fmt"
 	"net/url"
+	"reflect"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -37,6 +39,14 @@ type WidgetRenderData struct {
 	Template string
 	// Data contains any widget dependent data used to render the widget.
 	Data interface{}
+	// Localized is true if the value in Data is formatted for the locale
+	// of the form instead of the format of the HTML5 input type, so it
+	// should be rendered in a text input.
+	Localized bool
+	// OptionGroups contains the options of choice widgets with Groups or
+	// Disabled options, grouped for rendering. It is nil for other
+	// widgets. See GroupOptions.
+	OptionGroups []SelectOptionGroup
 }
 
 type Widget interface {
@@ -54,7 +64,9 @@ type WidgetBase struct {
 	Errors []string
 	// HTML classes to assign.
 	Classes []string
-	form    *Form
+	// Validators are run on the value filled into the app struct.
+	Validators []Validator
+	form       *Form
 }
 
 // Widget returns the corresponding widget.
@@ -65,18 +77,33 @@ func (w WidgetBase) Widget() Widget {
 func (w WidgetBase) GetRenderData() WidgetRenderData {
 	value, err := w.form.getNestedField(w.Id)
 	if err != nil {
-		panic(fmt.Sprintf("form: Could not find field %q in data: %v", w.Id, err))
+		panic(err)
 	}
 	return WidgetRenderData{
 		WidgetBase: w,
 		Template:   "text",
-		Data:       value.Interface()}
+		Data:       fieldInterface(value)}
 }
 
 func (w *WidgetBase) Base() *WidgetBase {
 	return w
 }
 
+// validate runs the widget's validators on the given value and adds
+// their translated messages to the widget's errors.
+//
+// Returns true iff all validators accept the value.
+func (w *WidgetBase) validate(value interface{}) bool {
+	valid := true
+	for _, validator := range w.Validators {
+		if err := validator.Validate(value); err != nil {
+			w.Errors = append(w.Errors, w.errorMessage(err))
+			valid = false
+		}
+	}
+	return valid
+}
+
 type TextWidget struct {
 	WidgetBase
 	MinLength       int
@@ -93,7 +120,7 @@ func (w *TextWidget) GetRenderData() WidgetRenderData {
 func (w *TextWidget) Fill(values url.Values) bool {
 	w.Errors = nil
 	value := values.Get(w.Id)
-	w.form.findNestedField(w.Id, value, false)
+	w.form.setField(w.Id, value)
 	validated := true
 	if len(value) < w.MinLength {
 		validated = false
@@ -104,12 +131,24 @@ func (w *TextWidget) Fill(values url.Values) bool {
 		}
 	}
 	if !validated {
-		w.Errors = append(w.Errors, w.ValidationError)
-		return false
+		w.Errors = append(w.Errors, w.translate(w.ValidationError, nil))
 	}
-	return true
+	return w.validate(value) && validated
 }
 
+func (w *TextWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	return verifyAssignable(id, t, stringType)
+}
+
+// PasswordWidget is a TextWidget for passwords. The password is never
+// included in the render data.
+//
+// If VerifyLabel is set, the password has to be entered a second time
+// in an input named like the widget's id with the suffix "--verify".
+//
+// The Data of the render data is a map with the keys "Verify" (true if
+// the password has to be verified), "VerifyId", "VerifyLabel" and
+// "VerifyDescription".
 type PasswordWidget struct {
 	TextWidget
 	// If the user has to repeat the password to verify it, specify at
@@ -120,9 +159,25 @@ type PasswordWidget struct {
 func (w PasswordWidget) GetRenderData() WidgetRenderData {
 	rd := w.TextWidget.GetRenderData()
 	rd.Template = "password"
+	rd.Data = map[string]interface{}{
+		"Verify":            w.VerifyLabel != "",
+		"VerifyId":          w.Id + "--verify",
+		"VerifyLabel":       w.form.translate(w.VerifyLabel, nil),
+		"VerifyDescription": w.form.translate(w.VerifyDescription, nil),
+	}
 	return rd
 }
 
+func (w *PasswordWidget) Fill(values url.Values) bool {
+	valid := w.TextWidget.Fill(values)
+	if w.VerifyLabel != "" &&
+		values.Get(w.Id) != values.Get(w.Id+"--verify") {
+		w.addError(w.VerifyError, "htmlwidgets.passwords-mismatch", nil)
+		return false
+	}
+	return valid
+}
+
 type TextAreaWidget struct {
 	WidgetBase
 	MinLength       int
@@ -138,16 +193,19 @@ func (w *TextAreaWidget) GetRenderData() WidgetRenderData {
 func (w *TextAreaWidget) Fill(values url.Values) bool {
 	w.Errors = nil
 	value := values.Get(w.Id)
-	w.form.findNestedField(w.Id, value, false)
+	w.form.setField(w.Id, value)
 	validated := true
 	if len(value) < w.MinLength {
 		validated = false
 	}
 	if !validated {
-		w.Errors = append(w.Errors, w.ValidationError)
-		return false
+		w.Errors = append(w.Errors, w.translate(w.ValidationError, nil))
 	}
-	return true
+	return w.validate(value) && validated
+}
+
+func (w *TextAreaWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	return verifyAssignable(id, t, stringType)
 }
 
 type BoolWidget struct{ WidgetBase }
@@ -159,31 +217,21 @@ func (w *BoolWidget) GetRenderData() WidgetRenderData {
 }
 
 func (w *BoolWidget) Fill(values url.Values) bool {
+	w.Errors = nil
+	value := false
 	if len(values[w.Id]) != 0 {
-		if v, err := strconv.ParseBool(values[w.Id][0]); err == nil {
-			w.form.findNestedField(w.Id, v, false)
+		v, err := strconv.ParseBool(values[w.Id][0])
+		if err != nil {
+			return true
 		}
-	} else {
-		w.form.findNestedField(w.Id, false, false)
+		value = v
 	}
-	return true
+	w.form.setField(w.Id, value)
+	return w.validate(value)
 }
 
-type IntegerWidget struct{ WidgetBase }
-
-func (w *IntegerWidget) GetRenderData() WidgetRenderData {
-	rd := w.Base().GetRenderData()
-	rd.Template = "text"
-	return rd
-}
-
-func (w *IntegerWidget) Fill(values url.Values) bool {
-	v, err := strconv.ParseInt(values[w.Id][0], 0, 0)
-	if err != nil {
-		return false
-	}
-	w.form.findNestedField(w.Id, int(v), false)
-	return true
+func (w *BoolWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	return verifyAssignable(id, t, reflect.TypeOf(false))
 }
 
 // SelectOption is an option to choose from in a SelectWidget
@@ -193,32 +241,92 @@ type SelectOption struct {
 }
 
 // SelectWidget allows to choose one from multiple options.
+//
+// The value of the chosen option is converted by the Codec to the type
+// of the target field. If the type can't be determined, a string is
+// filled in.
+//
+// The options are either given by Options or computed by Provider each
+// time the widget is rendered or filled. If no value is submitted,
+// the first enabled option is chosen. Values which are not among the
+// enabled options are rejected.
 type SelectWidget struct {
 	WidgetBase
 	Options []SelectOption
+	// Provider computes the options if set. Options is ignored then.
+	Provider OptionsProvider
+	// Groups maps option values to the labels of their option groups.
+	// Consecutive options with the same group are rendered in one
+	// <optgroup>.
+	Groups map[string]string
+	// Disabled contains the values of options which are rendered, but
+	// can't be chosen.
+	Disabled []string
+	// InvalidError is the message if a value is not one of the enabled
+	// options. If empty, a default message is used.
+	InvalidError string
+	// Codec converts between option values and the values of the app
+	// struct. If nil, DefaultCodec is used.
+	Codec Codec
 }
 
 func (w *SelectWidget) Fill(values url.Values) bool {
-	value := w.Options[0].Value
-	if len(values[w.Id]) >= 1 {
-		for i, option := range w.Options {
-			if option.Value == values.Get(w.Id) {
-				value = option.Value
-				w.Options[i].Selected = true
-			} else {
-				w.Options[i].Selected = false
-			}
+	w.Errors = nil
+	options, err := currentOptions(w.Options, w.Provider)
+	if err != nil {
+		w.Errors = append(w.Errors, err.Error())
+		return false
+	}
+	value := ""
+	for _, option := range options {
+		if !isDisabled(w.Disabled, option.Value) {
+			value = option.Value
+			break
 		}
 	}
-	w.form.findNestedField(w.Id, value, false)
-	return true
+	if submitted := values[w.Id]; len(submitted) != 0 {
+		if !validOption(options, w.Disabled, submitted[0]) {
+			w.addError(w.InvalidError, "htmlwidgets.invalid-option", nil)
+			return false
+		}
+		value = submitted[0]
+	}
+	decoded, err := codecOrDefault(w.Codec).Decode(value,
+		w.form.targetType(w.Id, stringType))
+	if err != nil {
+		w.addError(w.InvalidError, "htmlwidgets.invalid-option", nil)
+		return false
+	}
+	w.form.setField(w.Id, decoded.Interface())
+	return w.validate(decoded.Interface())
+}
+
+func (w *SelectWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	return verifyCodec(id, w.Codec, t)
 }
 
 func (w SelectWidget) GetRenderData() WidgetRenderData {
-	return WidgetRenderData{
+	rd := WidgetRenderData{
 		WidgetBase: w.WidgetBase,
-		Template:   "select",
-		Data:       w.Options}
+		Template:   "select"}
+	options, err := currentOptions(w.Options, w.Provider)
+	if err != nil {
+		rd.Errors = append(rd.Errors, err.Error())
+		return rd
+	}
+	value, err := w.form.getNestedField(w.Id)
+	if err != nil {
+		panic(err)
+	}
+	if value.IsValid() {
+		encoded, err := codecOrDefault(w.Codec).Encode(value)
+		if err == nil && validOption(options, w.Disabled, encoded) {
+			options = markSelected(options, encoded)
+		}
+	}
+	rd.Data = w.form.translateOptions(options)
+	rd.OptionGroups = w.form.groupOptions(options, w.Groups, w.Disabled)
+	return rd
 }
 
 type HiddenWidget struct {
@@ -232,71 +340,239 @@ func (w *HiddenWidget) GetRenderData() WidgetRenderData {
 }
 
 func (w *HiddenWidget) Fill(values url.Values) bool {
+	w.Errors = nil
 	value := values.Get(w.Id)
-	w.form.findNestedField(w.Id, value, false)
-	return true
+	w.form.setField(w.Id, value)
+	return w.validate(value)
+}
+
+func (w *HiddenWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	return verifyAssignable(id, t, stringType)
 }
 
-// FileWidget is a file upload widget that can be used to render a
-// HTML file input. It will ignore any uploaded file, you have to
-// process it b yourself.
+// StructWidget groups child widgets for the fields of a nested struct,
+// e.g. an address with street and city. It can be added to a form or
+// used as InnerWidget of a ListWidget for slices of structs.
+//
+// The ids of the child widgets are relative to the id of the
+// StructWidget, so a child "City" of a StructWidget "Address" fills the
+// field "Address.City".
 //
-// If you add this widget to a Form, the EncTypeAttr ob the RenderData
-// will be set on rendering.
-type FileWidget struct {
+// It is rendered with the "struct" template. The Data of the render
+// data contains the render data of the children as "Fields".
+type StructWidget struct {
 	WidgetBase
+	// Widgets are the child widgets, added with AddWidget.
+	Widgets []Widget
+	// ids are the relative ids of the child widgets.
+	ids []string
+	// childErrors are the errors of the child widgets found by Fill.
+	childErrors [][]string
 }
 
-func (w *FileWidget) GetRenderData() WidgetRenderData {
-	rd := w.Base().GetRenderData()
-	rd.Template = "file"
-	return rd
+// AddWidget adds a child widget with the given relative id and sets
+// the given attributes.
+//
+// It returns the added widget.
+func (w *StructWidget) AddWidget(widget Widget, id, label,
+	description string) Widget {
+	base := widget.Base()
+	base.Id = id
+	base.Label = label
+	base.Description = description
+	w.Widgets = append(w.Widgets, widget)
+	w.ids = append(w.ids, id)
+	return widget
+}
+
+// prepareChild sets the full id and the form of the child widget with
+// the given index.
+func (w *StructWidget) prepareChild(i int) *WidgetBase {
+	base := w.Widgets[i].Base()
+	base.Id = w.Id + "." + w.ids[i]
+	base.form = w.form
+	return base
+}
+
+func (w *StructWidget) GetRenderData() WidgetRenderData {
+	var fields []WidgetRenderData
+	for i, child := range w.Widgets {
+		base := w.prepareChild(i)
+		if len(w.childErrors) == len(w.Widgets) {
+			base.Errors = w.childErrors[i]
+		}
+		fields = append(fields, w.form.prepareRenderData(
+			child.GetRenderData()))
+	}
+	return WidgetRenderData{
+		WidgetBase: w.WidgetBase,
+		Template:   "struct",
+		Data:       map[string]interface{}{"Fields": fields},
+	}
 }
 
-func (w *FileWidget) Fill(values url.Values) bool {
-	return true
+func (w *StructWidget) Fill(values url.Values) bool {
+	w.Errors = nil
+	w.childErrors = nil
+	valid := true
+	for i, child := range w.Widgets {
+		base := w.prepareChild(i)
+		if !child.Fill(values) {
+			valid = false
+		}
+		w.childErrors = append(w.childErrors, base.Errors)
+	}
+	value, err := w.form.getNestedField(w.Id)
+	if err != nil {
+		panic(err)
+	}
+	return w.validate(value.Interface()) && valid
+}
+
+func (w *StructWidget) childWidgets() []Widget {
+	for i := range w.Widgets {
+		w.prepareChild(i)
+	}
+	return w.Widgets
+}
+
+func (w *StructWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	var errs FieldErrors
+	for i, child := range w.Widgets {
+		errs = append(errs, verifyChild(child, t, w.ids[i],
+			id+"."+w.ids[i])...)
+	}
+	return errs
+}
+
+// clone returns a copy of the widget with copies of the child widgets.
+func (w *StructWidget) clone() Widget {
+	clone := *w
+	clone.Errors = nil
+	clone.childErrors = nil
+	clone.Widgets = make([]Widget, len(w.Widgets))
+	for i, child := range w.Widgets {
+		clone.Widgets[i] = cloneWidget(child)
+	}
+	clone.ids = append([]string(nil), w.ids...)
+	return &clone
 }
 
+// ListWidget is a widget for slices rendering a widget for each item.
+//
+// The widgets of the items are created by NewInnerWidget or, if it's
+// nil, as copies of InnerWidget, which is never changed itself. They
+// keep the label, description, validators and other settings of
+// InnerWidget. Widgets with unexported pointers, maps or slices shared
+// by the copies should implement their own copying or be created by
+// NewInnerWidget.
+//
+// The items are submitted with ids like "<id>.<index>". The list is
+// changed by the following actions, which are submitted as parameter
+// "htmlwidgets-action--<action>" with the given value:
+//
+//	add-to-list       <id>          append an empty item
+//	remove-from-list  <id>.<index>  remove the item
+//	insert-before     <id>.<index>  insert an empty item before the item
+//	move-up           <id>.<index>  swap the item with the previous one
+//	move-down         <id>.<index>  swap the item with the next one
+//	reorder           <id>          reorder the items as given by the
+//	                                parameter "<id>--order", a comma
+//	                                separated list of the old indexes
+//	                                in their new order (e.g. "2,0,1")
+//
+// The buttons of the move and insert actions are only rendered if
+// their labels are set. The render data contains CanAdd and CanRemove,
+// which are false if MaxItems or MinItems have been reached.
 type ListWidget struct {
 	WidgetBase
-	InnerWidget           Widget
+	// InnerWidget is the prototype of the item widgets.
+	InnerWidget Widget
+	// NewInnerWidget creates the widget of an item if set.
+	NewInnerWidget        func() Widget
 	AddLabel, RemoveLabel string
+	// InsertLabel, MoveUpLabel and MoveDownLabel are the labels of the
+	// buttons of the corresponding actions.
+	InsertLabel, MoveUpLabel, MoveDownLabel string
+	// MinItems and MaxItems limit the number of items. Zero means no
+	// limit. Actions exceeding the limits are ignored.
+	MinItems, MaxItems int
+	// MinItemsError and MaxItemsError are the messages if there are too
+	// few or too many items. If empty, default messages are used.
+	MinItemsError, MaxItemsError string
+	// items are the widgets of the items.
+	items []Widget
+}
+
+// ListItemRenderData is the render data of an item of a ListWidget.
+type ListItemRenderData struct {
+	// Field is the render data of the inner widget.
+	Field WidgetRenderData
+	Index int
+	// First and Last are true for the first and last item.
+	First, Last bool
 }
 
 func (w *ListWidget) GetRenderData() WidgetRenderData {
 	innerValues, err := w.form.getNestedField(w.Id)
 	if err != nil {
-		panic(fmt.Sprintf("Could not find field %q in data", w.Id))
+		panic(err)
+	}
+	if innerValues.Kind() != reflect.Slice {
+		panic(fieldError(w.Id, ErrTypeMismatch,
+			"ListWidget needs a slice, got %v", innerValues.Type()))
 	}
 	var innerRenderData []WidgetRenderData
-	for i := 0; i < innerValues.Len(); i++ {
-		*(w.InnerWidget.Base()) = WidgetBase{
-			Id:   fmt.Sprintf("%v.%d", w.Id, i),
-			form: w.form,
-		}
-		renderData := w.InnerWidget.GetRenderData()
+	var items []ListItemRenderData
+	w.alignItems(innerValues.Len())
+	for i, item := range w.items {
+		w.setItem(item, i)
+		renderData := w.form.prepareRenderData(item.GetRenderData())
 		innerRenderData = append(innerRenderData,
 			renderData)
+		items = append(items, ListItemRenderData{
+			Field: renderData,
+			Index: i,
+			First: i == 0,
+			Last:  i == innerValues.Len()-1,
+		})
 	}
 	return WidgetRenderData{
 		WidgetBase: w.WidgetBase,
 		Template:   "list",
 		Data: map[string]interface{}{
-			"Fields":      innerRenderData,
-			"AddLabel":    w.AddLabel,
-			"RemoveLabel": w.RemoveLabel,
+			"Fields":        innerRenderData,
+			"Items":         items,
+			"AddLabel":      w.form.translate(w.AddLabel, nil),
+			"RemoveLabel":   w.form.translate(w.RemoveLabel, nil),
+			"InsertLabel":   w.form.translate(w.InsertLabel, nil),
+			"MoveUpLabel":   w.form.translate(w.MoveUpLabel, nil),
+			"MoveDownLabel": w.form.translate(w.MoveDownLabel, nil),
+			"CanAdd":        w.canAdd(innerValues.Len()),
+			"CanRemove":     w.canRemove(innerValues.Len()),
 		},
 	}
 }
 
 func (w *ListWidget) Fill(values url.Values) bool {
-	valid := true
-	addTo := values.Get("htmlwidgets-action--add-to-list") == w.Id
-	var remove []string
-	var maxIndex int
+	valid, _ := w.FillActions(values)
+	return valid
+}
 
-	// Find highest index
-	re := regexp.MustCompile("^" + w.Id + `\.(\d)$`)
+// FillActions fills the list and handles the list actions.
+func (w *ListWidget) FillActions(values url.Values) (bool, []FillAction) {
+	w.Errors = nil
+	w.items = nil
+	valid := true
+	addTo := values.Get(ActionPrefix+"add-to-list") == w.Id
+	var remove []int
+	var actions []FillAction
+	maxIndex := -1
+	added := false
+
+	// Find highest index. Composite inner widgets submit keys like
+	// "<id>.<index>.<field>".
+	re := regexp.MustCompile("^" + regexp.QuoteMeta(w.Id) + `\.(\d+)(?:\.|$)`)
 	for key, _ := range values {
 		matches := re.FindStringSubmatch(key)
 		if len(matches) == 2 {
@@ -306,38 +582,56 @@ func (w *ListWidget) Fill(values url.Values) bool {
 			}
 		}
 	}
+	// Actions exceeding MinItems or MaxItems are ignored and not
+	// reported.
 	if addTo {
-		maxIndex += 1
+		if w.canAdd(maxIndex + 1) {
+			actions = append(actions, FillAction{Name: "add-to-list",
+				WidgetId: w.Id, Index: maxIndex + 1})
+			maxIndex += 1
+		} else {
+			addTo = false
+		}
 	}
 
-	// Fill values into inner fields
+	// Fill values into inner fields at the submitted indexes, which
+	// requires the list to have at least as many items.
+	w.growList(maxIndex + 1)
 	for i := 0; i <= maxIndex; i++ {
 		id := fmt.Sprintf("%v.%d", w.Id, i)
-		if values.Get("htmlwidgets-action--remove-from-list") == id {
-			remove = append(remove, id)
-			valid = false
-			continue
+		if values.Get(ActionPrefix+"remove-from-list") == id {
+			if w.canRemove(maxIndex + 1) {
+				actions = append(actions, FillAction{Name: "remove-from-list",
+					WidgetId: w.Id, Index: i})
+				remove = append(remove, i)
+				continue
+			}
 		}
-		if _, ok := values[id]; !ok {
-			if !addTo {
-				remove = append(remove, id)
-			} else {
+		item := w.newItem()
+		w.setItem(item, i)
+		if !itemSubmitted(values, id) {
+			if addTo {
+				// The added item is neither filled nor validated
+				// before it has been submitted.
 				addTo = false
-				valid = false
+				added = true
+				w.items = append(w.items, item)
+				continue
 			}
+			remove = append(remove, i)
 		}
-		*(w.InnerWidget.Base()) = WidgetBase{
-			Id:   id,
-			form: w.form,
-		}
-		if !w.InnerWidget.Fill(values) {
+		if !item.Fill(values) {
 			valid = false
 		}
+		if len(remove) == 0 || remove[len(remove)-1] != i {
+			w.items = append(w.items, item)
+		}
 	}
 
-	// Remove fields as requested by the remove action
-	for _, id := range remove {
-		w.form.findNestedField(id, nil, true)
+	// Remove fields as requested by the remove action, starting with
+	// the last one to keep the indexes of the others.
+	for j := len(remove) - 1; j >= 0; j-- {
+		w.form.removeField(fmt.Sprintf("%v.%d", w.Id, remove[j]))
 	}
 
 	// Remove fields after the maximum index
@@ -345,11 +639,258 @@ func (w *ListWidget) Fill(values url.Values) bool {
 	if err != nil {
 		panic(err)
 	}
-	for i := maxIndex + 1; i < field.Len(); i++ {
-		id := fmt.Sprintf("%v.%d", w.Id, i)
-		w.form.findNestedField(id, nil, true)
+	if field.Kind() != reflect.Slice {
+		panic(fieldError(w.Id, ErrTypeMismatch,
+			"ListWidget needs a slice, got %v", field.Type()))
 	}
-	return valid
+	for field.Len() > maxIndex+1-len(remove) {
+		id := fmt.Sprintf("%v.%d", w.Id, field.Len()-1)
+		w.form.removeField(id)
+		if field, err = w.form.getNestedField(w.Id); err != nil {
+			panic(err)
+		}
+	}
+	if added {
+		length := maxIndex + 1 - len(remove)
+		items := reflect.MakeSlice(field.Type(), length, length)
+		// Items at the index of the added item are stale.
+		if field.Len() >= length {
+			field = field.Slice(0, length-1)
+		}
+		reflect.Copy(items, field)
+		w.form.setField(w.Id, items.Interface())
+		if field, err = w.form.getNestedField(w.Id); err != nil {
+			panic(err)
+		}
+	}
+	w.alignItems(field.Len())
+
+	// Reorder fields as requested by the move, insert and reorder
+	// actions.
+	if order, action, ok := w.requestedOrder(values, field.Len()); ok {
+		actions = append(actions, action)
+		w.reorder(field, order)
+		if field, err = w.form.getNestedField(w.Id); err != nil {
+			panic(err)
+		}
+	}
+	if w.MinItems > 0 && field.Len() < w.MinItems {
+		w.addError(w.MinItemsError, "htmlwidgets.min-items",
+			map[string]interface{}{"min": w.MinItems})
+		valid = false
+	}
+	if w.MaxItems > 0 && field.Len() > w.MaxItems {
+		w.addError(w.MaxItemsError, "htmlwidgets.max-items",
+			map[string]interface{}{"max": w.MaxItems})
+		valid = false
+	}
+	return w.validate(field.Interface()) && valid, actions
+}
+
+func (w *ListWidget) childWidgets() []Widget {
+	for i, item := range w.items {
+		w.setItem(item, i)
+	}
+	return w.items
+}
+
+func (w *ListWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	if t.Kind() != reflect.Slice {
+		return FieldErrors{fieldError(id, ErrTypeMismatch,
+			"ListWidget needs a slice, got %v", t)}
+	}
+	return verifyChild(w.newItem(), t.Elem(), "", id+".0")
+}
+
+// itemSubmitted returns true if any of the given values belongs to the
+// list item with the given id.
+func itemSubmitted(values url.Values, id string) bool {
+	for key := range values {
+		if key == id || strings.HasPrefix(key, id+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// growList appends zero items to the list until it has the given
+// length.
+func (w *ListWidget) growList(length int) {
+	field, err := w.form.getNestedField(w.Id)
+	if err != nil {
+		panic(err)
+	}
+	if field.Kind() != reflect.Slice {
+		panic(fieldError(w.Id, ErrTypeMismatch,
+			"ListWidget needs a slice, got %v", field.Type()))
+	}
+	if field.Len() >= length {
+		return
+	}
+	items := reflect.MakeSlice(field.Type(), length, length)
+	reflect.Copy(items, field)
+	w.form.setField(w.Id, items.Interface())
+}
+
+// canAdd returns true if an item may be added to a list of the given
+// length.
+func (w *ListWidget) canAdd(length int) bool {
+	return w.MaxItems <= 0 || length < w.MaxItems
+}
+
+// canRemove returns true if an item may be removed from a list of the
+// given length.
+func (w *ListWidget) canRemove(length int) bool {
+	return length > w.MinItems
+}
+
+// newItem returns a new widget for an item.
+func (w *ListWidget) newItem() Widget {
+	if w.NewInnerWidget != nil {
+		return w.NewInnerWidget()
+	}
+	return cloneWidget(w.InnerWidget)
+}
+
+// setItem sets the id and form of the given item widget.
+func (w *ListWidget) setItem(item Widget, index int) {
+	base := item.Base()
+	base.Id = fmt.Sprintf("%v.%d", w.Id, index)
+	base.form = w.form
+}
+
+// alignItems shortens the item widgets or adds new ones to get the
+// given number of items.
+func (w *ListWidget) alignItems(length int) {
+	for len(w.items) < length {
+		w.items = append(w.items, w.newItem())
+	}
+	w.items = w.items[:length]
+}
+
+// clone returns a copy of the widget without item widgets.
+func (w *ListWidget) clone() Widget {
+	clone := *w
+	clone.Errors = nil
+	clone.items = nil
+	return &clone
+}
+
+// requestedOrder returns the order of the items requested by a move,
+// insert or reorder action. The order contains the old index of each
+// item or -1 for a new item.
+func (w *ListWidget) requestedOrder(values url.Values, length int) (
+	order []int, action FillAction, ok bool) {
+	order = make([]int, length)
+	for i := range order {
+		order[i] = i
+	}
+	for _, name := range []string{"insert-before", "move-up", "move-down"} {
+		index, found := w.itemIndex(values.Get(ActionPrefix + name))
+		if !found || index >= length {
+			continue
+		}
+		action = FillAction{Name: name, WidgetId: w.Id, Index: index}
+		switch {
+		case name == "insert-before" && !w.canAdd(length):
+			// Inserts exceeding MaxItems are ignored.
+			return nil, action, false
+		case name == "insert-before":
+			order = append(order[:index],
+				append([]int{-1}, order[index:]...)...)
+		case name == "move-up" && index > 0:
+			order[index-1], order[index] = order[index], order[index-1]
+		case name == "move-down" && index < length-1:
+			order[index+1], order[index] = order[index], order[index+1]
+		}
+		return order, action, true
+	}
+	if values.Get(ActionPrefix+"reorder") != w.Id {
+		return nil, action, false
+	}
+	action = FillAction{Name: "reorder", WidgetId: w.Id}
+	parts := strings.Split(values.Get(w.Id+"--order"), ",")
+	seen := make(map[int]bool, length)
+	for i, part := range parts {
+		index, err := strconv.Atoi(strings.TrimSpace(part))
+		if len(parts) != length || err != nil || index < 0 ||
+			index >= length || seen[index] {
+			return nil, action, false
+		}
+		seen[index] = true
+		order[i] = index
+	}
+	return order, action, true
+}
+
+// itemIndex returns the index of the item with the given id.
+func (w *ListWidget) itemIndex(id string) (int, bool) {
+	if !strings.HasPrefix(id, w.Id+".") {
+		return 0, false
+	}
+	index, err := strconv.Atoi(id[len(w.Id)+1:])
+	return index, err == nil && index >= 0
+}
+
+// reorder sets the list to the items of the given field in the given
+// order. New items are zero values.
+func (w *ListWidget) reorder(field reflect.Value, order []int) {
+	items := reflect.MakeSlice(field.Type(), len(order), len(order))
+	widgets := make([]Widget, len(order))
+	for i, index := range order {
+		if index >= 0 {
+			items.Index(i).Set(field.Index(index))
+			widgets[i] = w.items[index]
+		} else {
+			widgets[i] = w.newItem()
+		}
+	}
+	w.form.setField(w.Id, items.Interface())
+	w.items = widgets
+}
+
+// parentWidget is implemented by widgets containing other widgets like
+// StructWidget and ListWidget.
+type parentWidget interface {
+	// childWidgets returns the child widgets with their full ids.
+	childWidgets() []Widget
+}
+
+// collectErrors adds the errors of the given widget and its children
+// to errors, keyed by their ids.
+func collectErrors(widget Widget, errors map[string][]string) {
+	if base := widget.Base(); len(base.Errors) > 0 {
+		errors[base.Id] = append(errors[base.Id], base.Errors...)
+	}
+	if parent, ok := widget.(parentWidget); ok {
+		for _, child := range parent.childWidgets() {
+			collectErrors(child, errors)
+		}
+	}
+}
+
+// cloner is implemented by widgets which need more than a shallow copy
+// to be cloned, e.g. because they contain other widgets.
+type cloner interface {
+	clone() Widget
+}
+
+// cloneWidget returns a copy of the given widget without errors.
+//
+// It panics if widget is not a pointer to a struct.
+func cloneWidget(widget Widget) Widget {
+	if c, ok := widget.(cloner); ok {
+		return c.clone()
+	}
+	value := reflect.ValueOf(widget)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("htmlwidgets: Can't copy widget of type %T", widget))
+	}
+	clone := reflect.New(value.Elem().Type())
+	clone.Elem().Set(value.Elem())
+	cloned := clone.Interface().(Widget)
+	cloned.Base().Errors = nil
+	return cloned
 }
 
 // TimeWidget is a widget that allows to set a date and time in the
@@ -357,9 +898,19 @@ func (w *ListWidget) Fill(values url.Values) bool {
 //
 // It tries to parse values as defined in the constants RFC3339,
 // RFC3339Nano and RFC3339Short and renders the time as RFC3339Short.
+// If the form has a locale with a LocaleFormat, the DateTimeLayouts of
+// the locale are tried first and used for rendering.
+//
+// If no value is submitted, the zero time is filled in. Values that
+// can't be parsed are rejected with an error.
 type TimeWidget struct {
 	WidgetBase
 	Location *time.Location
+	// ParseError is the message for values that can't be parsed. If
+	// empty, a default message is used.
+	ParseError string
+	// invalid is the last submitted value if it could not be parsed.
+	invalid *string
 }
 
 func (w *TimeWidget) GetRenderData() WidgetRenderData {
@@ -368,30 +919,70 @@ func (w *TimeWidget) GetRenderData() WidgetRenderData {
 	}
 	value, err := w.form.getNestedField(w.Id)
 	if err != nil {
-		panic(fmt.Sprintf("Could not find field %q in data", w.Id))
+		panic(err)
 	}
-	timeValue := value.Interface().(time.Time).In(w.Location).Format(RFC3339Short)
-	return WidgetRenderData{
+	// Nil pointers are rendered as empty value.
+	field := fieldInterface(value)
+	t, ok := field.(time.Time)
+	if !ok && field != nil {
+		panic(fieldError(w.Id, ErrTypeMismatch,
+			"TimeWidget needs a time.Time, got %v", value.Type()))
+	}
+	rd := WidgetRenderData{
 		WidgetBase: w.WidgetBase,
 		Template:   "time",
-		Data:       timeValue}
+		Data:       ""}
+	if ok {
+		rd.Data = t.In(w.Location).Format(RFC3339Short)
+	}
+	if lf := w.form.localeFormat(); lf != nil && len(lf.DateTimeLayouts) > 0 {
+		rd.Localized = true
+		rd.Data = ""
+		if ok && !t.IsZero() {
+			rd.Data = t.In(w.Location).Format(lf.DateTimeLayouts[0])
+		}
+	}
+	if w.invalid != nil {
+		rd.Data = *w.invalid
+	}
+	return rd
 }
 
 func (w *TimeWidget) Fill(values url.Values) bool {
+	w.Errors = nil
 	if w.Location == nil {
 		w.Location = time.UTC
 	}
-	value := values.Get(w.Id)
-	v, err := time.ParseInLocation(RFC3339Nano, value, w.Location)
-	if err != nil {
-		v, err = time.ParseInLocation(RFC3339, value, w.Location)
+	w.invalid = nil
+	value := strings.TrimSpace(values.Get(w.Id))
+	if value == "" {
+		w.form.clearField(w.Id, timeType)
+		return w.validate(time.Time{})
 	}
-	if err != nil {
-		v, err = time.ParseInLocation(RFC3339Short, value, w.Location)
+	var layouts []string
+	if lf := w.form.localeFormat(); lf != nil {
+		layouts = append(layouts, lf.DateTimeLayouts...)
 	}
+	layouts = append(layouts, RFC3339Nano, RFC3339, RFC3339Short)
+	v, err := parseTime(value, w.Location, layouts...)
 	if err != nil {
-		v = time.Time{}
+		w.invalid = &value
+		w.addError(w.ParseError, "htmlwidgets.time", nil)
+		return false
+	}
+	w.form.setField(w.Id, v)
+	return w.validate(v)
+}
+
+func (w *TimeWidget) verifyField(id string, t reflect.Type) FieldErrors {
+	return verifyAssignable(id, t, timeType)
+}
+
+// defaultMessage returns the given message or the default if it's
+// empty.
+func defaultMessage(message, defaultMessage string) string {
+	if message == "" {
+		return defaultMessage
 	}
-	w.form.findNestedField(w.Id, v, false)
-	return true
+	return message
 }
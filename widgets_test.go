@@ -17,6 +17,8 @@
 package htmlwidgets
 
 import (
+	"errors"
+	"fmt"
 	"net/url"
 	"reflect"
 	"testing"
@@ -110,6 +112,78 @@ func TestSelectWidget(t *testing.T) {
 	})
 }
 
+func TestSelectWidgetOptions(t *testing.T) {
+	data := TestSelectWidgetData{}
+	form := NewForm(&data)
+	calls := 0
+	widget := &SelectWidget{
+		Provider: OptionsFunc(func() ([]SelectOption, error) {
+			calls++
+			if calls > 4 {
+				return nil, errors.New("Database down!")
+			}
+			return []SelectOption{
+				SelectOption{"old", "Old", false},
+				SelectOption{"foo", "Foo", false},
+				SelectOption{"bar", "Bar", false},
+			}, nil
+		}),
+		Groups:       map[string]string{"foo": "A", "bar": "A"},
+		Disabled:     []string{"old"},
+		InvalidError: "Invalid!",
+	}
+	form.AddWidget(widget, "Id", "", "")
+	if !form.Fill(url.Values{}) || data.Id != "foo" {
+		t.Errorf("Fill without value chose %q, expected first enabled option",
+			data.Id)
+	}
+	if form.Fill(url.Values{"Id": []string{"old"}}) || data.Id != "foo" ||
+		!reflect.DeepEqual(widget.Errors, []string{"Invalid!"}) {
+		t.Errorf("Fill with disabled option chose %q with errors %v", data.Id,
+			widget.Errors)
+	}
+	if form.Fill(url.Values{"Id": []string{"cruz"}}) || data.Id != "foo" {
+		t.Errorf("Fill with unknown option chose %q", data.Id)
+	}
+	expected := []SelectOption{
+		SelectOption{"old", "Old", false},
+		SelectOption{"foo", "Foo", true},
+		SelectOption{"bar", "Bar", false},
+	}
+	expectedGroups := []SelectOptionGroup{
+		{"", []GroupedOption{{expected[0], true}}},
+		{"A", []GroupedOption{{expected[1], false}, {expected[2], false}}},
+	}
+	if rd := form.RenderData().Widgets[0]; !reflect.DeepEqual(rd.Data, expected) ||
+		!reflect.DeepEqual(rd.OptionGroups, expectedGroups) {
+		t.Errorf("Render data is %#v, %#v, expected %#v, %#v", rd.Data,
+			rd.OptionGroups, expected, expectedGroups)
+	}
+	if form.Fill(url.Values{"Id": []string{"bar"}}) {
+		t.Errorf("Fill returned true although provider failed")
+	}
+	if !reflect.DeepEqual(widget.Errors, []string{"Database down!"}) {
+		t.Errorf("Errors are %v, expected provider error", widget.Errors)
+	}
+	if rd := form.RenderData().Widgets[0]; rd.Data != nil ||
+		!reflect.DeepEqual(rd.Errors, []string{"Database down!",
+			"Database down!"}) {
+		t.Errorf("Render data after provider error is %#v", rd)
+	}
+}
+
+func TestSelectWidgetEmptyValues(t *testing.T) {
+	data := TestSelectWidgetData{}
+	form := NewForm(&data)
+	form.AddWidget(&SelectWidget{Options: []SelectOption{
+		SelectOption{"foo", "Foo", false},
+		SelectOption{"bar", "Bar", false},
+	}}, "Id", "", "")
+	if !form.Fill(url.Values{"Id": []string{}}) || data.Id != "foo" {
+		t.Errorf("Fill with empty values chose %q", data.Id)
+	}
+}
+
 type TestHiddenWidgetData struct {
 	Id string
 }
@@ -137,7 +211,7 @@ func TestFileWidget(t *testing.T) {
 		URLValue:    "",
 		FilledValue: "",
 		EmptyValue:  "",
-		RenderData:  "",
+		RenderData:  FileRenderData{},
 		Template:    "file",
 	})
 }
@@ -203,6 +277,65 @@ func TestTextWidget(t *testing.T) {
 	})
 }
 
+type TestPasswordWidgetData struct {
+	Id string
+}
+
+func TestPasswordWidget(t *testing.T) {
+	testWidget(t, &WidgetTest{
+		Widget:      new(PasswordWidget),
+		AppStruct:   &TestPasswordWidgetData{},
+		URLValue:    "secret",
+		FilledValue: "secret",
+		EmptyValue:  "",
+		RenderData: map[string]interface{}{
+			"Verify":            false,
+			"VerifyId":          "Id--verify",
+			"VerifyLabel":       "",
+			"VerifyDescription": "",
+		},
+		Template: "password",
+	})
+}
+
+func TestPasswordWidgetVerify(t *testing.T) {
+	data := TestPasswordWidgetData{}
+	form := NewForm(&data)
+	widget := &PasswordWidget{VerifyLabel: "Repeat", VerifyDescription: "Again",
+		VerifyError: "Mismatch!"}
+	form.AddWidget(widget, "Id", "Label", "Description")
+	vals := url.Values{
+		"Id":         []string{"secret"},
+		"Id--verify": []string{"secreT"},
+	}
+	if form.Fill(vals) {
+		t.Errorf("Fill returned true for differing passwords")
+	}
+	renderData := form.RenderData().Widgets[0]
+	if !reflect.DeepEqual(renderData.Errors, []string{"Mismatch!"}) {
+		t.Errorf("Errors are %v, expected [Mismatch!]", renderData.Errors)
+	}
+	expected := map[string]interface{}{
+		"Verify":            true,
+		"VerifyId":          "Id--verify",
+		"VerifyLabel":       "Repeat",
+		"VerifyDescription": "Again",
+	}
+	if !reflect.DeepEqual(renderData.Data, expected) {
+		t.Errorf("Render data is %#v, expected %#v", renderData.Data, expected)
+	}
+	vals["Id--verify"] = []string{"secret"}
+	if !form.Fill(vals) {
+		t.Errorf("Fill returned false for matching passwords")
+	}
+	if data.Id != "secret" {
+		t.Errorf("Filled password is %q, expected %q", data.Id, "secret")
+	}
+	if errors := form.RenderData().Widgets[0].Errors; len(errors) != 0 {
+		t.Errorf("Errors are %v after valid fill", errors)
+	}
+}
+
 type TestTextAreaWidgetData struct {
 	Id string
 }
@@ -289,3 +422,375 @@ func TestListWidget(t *testing.T) {
 			data, expectedData)
 	}
 }
+
+func TestListWidgetActions(t *testing.T) {
+	tests := []struct {
+		Action, Value string
+		Order         string
+		Expected      []string
+		Errors        [][]string
+	}{
+		{"move-up", "Tags.1", "", []string{"b", "x", "c"},
+			[][]string{nil, []string{"Too short!"}, nil}},
+		{"move-up", "Tags.0", "", []string{"x", "b", "c"},
+			[][]string{[]string{"Too short!"}, nil, nil}},
+		{"move-down", "Tags.1", "", []string{"x", "c", "b"},
+			[][]string{[]string{"Too short!"}, nil, nil}},
+		{"move-down", "Tags.2", "", []string{"x", "b", "c"},
+			[][]string{[]string{"Too short!"}, nil, nil}},
+		{"insert-before", "Tags.0", "", []string{"", "x", "b", "c"},
+			[][]string{nil, []string{"Too short!"}, nil, nil}},
+		{"reorder", "Tags", "2, 0,1", []string{"c", "x", "b"},
+			[][]string{nil, []string{"Too short!"}, nil}},
+		{"reorder", "Tags", "2,2,1", []string{"x", "b", "c"},
+			[][]string{[]string{"Too short!"}, nil, nil}},
+	}
+	for i, test := range tests {
+		data := struct{ Tags []string }{}
+		form := NewForm(&data)
+		inner := new(TextWidget)
+		inner.Validators = []Validator{Regexp("^..$", "Too short!")}
+		form.AddWidget(&ListWidget{InnerWidget: inner}, "Tags", "", "")
+		values := url.Values{
+			"Tags.0":                             []string{"x"},
+			"Tags.1":                             []string{"bb"},
+			"Tags.2":                             []string{"cc"},
+			"Tags--order":                        []string{test.Order},
+			"htmlwidgets-action--" + test.Action: []string{test.Value},
+		}
+		result := form.FillValues(values)
+		for j, expected := range test.Expected {
+			if expected != "x" && expected != "" {
+				test.Expected[j] = expected + expected
+			}
+		}
+		if !reflect.DeepEqual(data.Tags, test.Expected) {
+			t.Errorf("Test %d: data is %v, expected %v", i, data.Tags,
+				test.Expected)
+		}
+		validOrder := test.Action != "reorder" || test.Order != "2,2,1"
+		if validOrder && (len(result.Actions) != 1 ||
+			result.Actions[0].Name != test.Action) {
+			t.Errorf("Test %d: actions are %v", i, result.Actions)
+		}
+		items := form.RenderData().Widgets[0].Data.(map[string]interface{})["Items"].([]ListItemRenderData)
+		for j, item := range items {
+			if !reflect.DeepEqual(item.Field.Errors, test.Errors[j]) {
+				t.Errorf("Test %d: errors of item %d are %v, expected %v", i, j,
+					item.Field.Errors, test.Errors[j])
+			}
+			if item.First != (j == 0) || item.Last != (j == len(items)-1) {
+				t.Errorf("Test %d: item %d has wrong First/Last", i, j)
+			}
+		}
+	}
+}
+
+func TestListWidgetLimits(t *testing.T) {
+	tests := []struct {
+		Values    url.Values
+		Expected  []string
+		Errors    []string
+		CanAdd    bool
+		CanRemove bool
+	}{
+		{url.Values{"Tags.0": []string{"a"}, "Tags.1": []string{"b"}},
+			[]string{"a", "b"}, nil, true, true},
+		{url.Values{"Tags.0": []string{"a"}, "Tags.1": []string{"b"},
+			"Tags.2": []string{"c"}},
+			[]string{"a", "b", "c"}, nil, false, true},
+		{url.Values{"Tags.0": []string{"a"}, "Tags.1": []string{"b"},
+			"Tags.2":                          []string{"c"},
+			"htmlwidgets-action--add-to-list": []string{"Tags"}},
+			[]string{"a", "b", "c"}, nil, false, true},
+		{url.Values{"Tags.0": []string{"a"}, "Tags.1": []string{"b"},
+			"Tags.2":                            []string{"c"},
+			"htmlwidgets-action--insert-before": []string{"Tags.0"}},
+			[]string{"a", "b", "c"}, nil, false, true},
+		{url.Values{"Tags.0": []string{"a"}, "Tags.1": []string{"b"},
+			"htmlwidgets-action--remove-from-list": []string{"Tags.0"}},
+			[]string{"b"}, nil, true, false},
+		{url.Values{"Tags.0": []string{"a"},
+			"htmlwidgets-action--remove-from-list": []string{"Tags.0"}},
+			[]string{"a"}, []string{"Please enter at least 2 items."}, true,
+			false},
+		{url.Values{"Tags.0": []string{"a"}, "Tags.1": []string{"b"},
+			"Tags.2": []string{"c"}, "Tags.3": []string{"d"}},
+			[]string{"a", "b", "c", "d"}, []string{"Too many!"}, false, true},
+	}
+	for i, test := range tests {
+		data := struct{ Tags []string }{[]string{"x", "y", "z", "w"}}
+		form := NewForm(&data)
+		form.AddWidget(&ListWidget{InnerWidget: new(TextWidget), MinItems: 1,
+			MaxItems: 3, MaxItemsError: "Too many!"}, "Tags", "", "")
+		if i == 5 {
+			form.WidgetById("Tags").(*ListWidget).MinItems = 2
+		}
+		result := form.FillValues(test.Values)
+		// Only the remove action of test 4 is within the limits.
+		if (len(result.Actions) == 1) != (i == 4) {
+			t.Errorf("Test %d: actions are %v", i, result.Actions)
+		}
+		if !reflect.DeepEqual(data.Tags, test.Expected) {
+			t.Errorf("Test %d: data is %v, expected %v", i, data.Tags,
+				test.Expected)
+		}
+		rd := form.RenderData().Widgets[0]
+		if !reflect.DeepEqual(rd.Errors, test.Errors) {
+			t.Errorf("Test %d: errors are %v, expected %v", i, rd.Errors,
+				test.Errors)
+		}
+		listData := rd.Data.(map[string]interface{})
+		if listData["CanAdd"] != test.CanAdd ||
+			listData["CanRemove"] != (test.CanRemove && i != 5) {
+			t.Errorf("Test %d: CanAdd is %v, CanRemove is %v", i,
+				listData["CanAdd"], listData["CanRemove"])
+		}
+	}
+}
+
+func TestListWidgetAddUnvalidated(t *testing.T) {
+	data := struct{ Tags []string }{[]string{"x", "y", "z"}}
+	form := NewForm(&data)
+	inner := new(TextWidget)
+	inner.Validators = []Validator{MinLength(2, "")}
+	form.AddWidget(&ListWidget{InnerWidget: inner}, "Tags", "", "")
+	result := form.FillValues(url.Values{
+		"Tags.0":                          []string{"ab"},
+		"Tags.1":                          []string{"cd"},
+		"htmlwidgets-action--add-to-list": []string{"Tags"},
+	})
+	if !result.Valid || len(result.Errors) > 0 {
+		t.Errorf("Adding an item gave result %v", result)
+	}
+	if !reflect.DeepEqual(data.Tags, []string{"ab", "cd", ""}) {
+		t.Errorf("Filled data is %v", data.Tags)
+	}
+	listData := form.RenderData().Widgets[0].Data.(map[string]interface{})
+	items := listData["Items"].([]ListItemRenderData)
+	if len(items) != 3 || len(items[2].Field.Errors) > 0 {
+		t.Errorf("Invalid items %v", items)
+	}
+}
+
+func TestListWidgetRemoveFromEmpty(t *testing.T) {
+	data := struct{ L []string }{}
+	form := NewForm(&data)
+	form.AddWidget(&ListWidget{InnerWidget: new(TextWidget)}, "L", "", "")
+	result, err := form.FillE(url.Values{
+		"L.0":                                  []string{"a"},
+		"L.1":                                  []string{"b"},
+		"L.2":                                  []string{"c"},
+		"htmlwidgets-action--remove-from-list": []string{"L.1"},
+	})
+	if err != nil {
+		t.Fatalf("FillE failed: %v", err)
+	}
+	if !reflect.DeepEqual(data.L, []string{"a", "c"}) {
+		t.Errorf("Filled data is %v", data.L)
+	}
+	if !reflect.DeepEqual(result.Actions, []FillAction{{
+		Name: "remove-from-list", WidgetId: "L", Index: 1}}) {
+		t.Errorf("Actions are %v", result.Actions)
+	}
+}
+
+func TestNestedWidgetErrors(t *testing.T) {
+	data := struct {
+		Tags    []string
+		Address TestAddress
+	}{}
+	form := NewForm(&data)
+	inner := new(TextWidget)
+	inner.Validators = []Validator{MinLength(2, "Too short!")}
+	form.AddWidget(&ListWidget{InnerWidget: inner}, "Tags", "", "")
+	form.AddWidget(newTestAddressWidget(), "Address", "", "")
+	result := form.FillValues(url.Values{
+		"Tags.0":       []string{"ab"},
+		"Tags.1":       []string{"c"},
+		"Address.City": []string{"Berlin"},
+	})
+	expected := map[string][]string{
+		"Tags.1":         []string{"Too short!"},
+		"Address.Street": []string{"Street required!"},
+	}
+	if result.Valid || !reflect.DeepEqual(result.Errors, expected) {
+		t.Errorf("Result is %v, expected errors %v", result, expected)
+	}
+}
+
+// testNameWidget is a composite widget filling the Name field of a
+// struct.
+type testNameWidget struct {
+	WidgetBase
+}
+
+func (w *testNameWidget) GetRenderData() WidgetRenderData {
+	value, _ := w.form.getNestedField(w.Id + ".Name")
+	return WidgetRenderData{WidgetBase: w.WidgetBase, Template: "text",
+		Data: value.Interface()}
+}
+
+func (w *testNameWidget) Fill(values url.Values) bool {
+	w.form.findNestedField(w.Id+".Name", values.Get(w.Id+".Name"), false)
+	return true
+}
+
+func TestListWidgetLargeLists(t *testing.T) {
+	data := map[string]interface{}{"Tags[x]+": make([]string, 15)}
+	form := NewForm(data)
+	form.AddWidget(&ListWidget{InnerWidget: new(TextWidget)}, "Tags[x]+", "",
+		"")
+	values := url.Values{"Tags[x]+0": []string{"invalid"}}
+	var expected []string
+	for i := 0; i < 15; i++ {
+		value := fmt.Sprintf("Tag %d", i)
+		values.Set(fmt.Sprintf("Tags[x]+.%d", i), value)
+		expected = append(expected, value)
+	}
+	values.Set("htmlwidgets-action--remove-from-list", "Tags[x]+.12")
+	result := form.FillValues(values)
+	expected = append(expected[:12], expected[13:]...)
+	if !reflect.DeepEqual(data["Tags[x]+"], expected) {
+		t.Errorf("Filled data is %v, expected %v", data["Tags[x]+"], expected)
+	}
+	if !reflect.DeepEqual(result.Actions, []FillAction{{
+		Name: "remove-from-list", WidgetId: "Tags[x]+", Index: 12}}) {
+		t.Errorf("Actions are %v", result.Actions)
+	}
+
+	type person struct{ Name string }
+	people := struct{ People []person }{}
+	form = NewForm(&people)
+	form.AddWidget(&ListWidget{InnerWidget: new(testNameWidget)}, "People",
+		"", "")
+	values = url.Values{"htmlwidgets-action--add-to-list": []string{"People"}}
+	var expectedPeople []person
+	for i := 0; i < 12; i++ {
+		name := fmt.Sprintf("Person %d", i)
+		values.Set(fmt.Sprintf("People.%d.Name", i), name)
+		expectedPeople = append(expectedPeople, person{name})
+	}
+	result = form.FillValues(values)
+	expectedPeople = append(expectedPeople, person{})
+	if !reflect.DeepEqual(people.People, expectedPeople) {
+		t.Errorf("Filled people are %v, expected %v", people.People,
+			expectedPeople)
+	}
+	if !reflect.DeepEqual(result.Actions, []FillAction{{
+		Name: "add-to-list", WidgetId: "People", Index: 12}}) {
+		t.Errorf("Actions are %v", result.Actions)
+	}
+}
+
+type TestAddress struct {
+	Street, City string
+}
+
+func newTestAddressWidget() *StructWidget {
+	widget := new(StructWidget)
+	street := widget.AddWidget(new(TextWidget), "Street", "Street", "")
+	street.Base().Validators = []Validator{Required("Street required!")}
+	widget.AddWidget(new(TextWidget), "City", "City", "Your city")
+	return widget
+}
+
+func TestStructWidget(t *testing.T) {
+	data := struct{ Address TestAddress }{}
+	form := NewForm(&data)
+	form.AddWidget(newTestAddressWidget(), "Address", "Address", "")
+	if form.Fill(url.Values{"Address.City": []string{"Berlin"}}) {
+		t.Errorf("Fill should fail without street")
+	}
+	if data.Address != (TestAddress{"", "Berlin"}) {
+		t.Errorf("Filled data is %v", data.Address)
+	}
+	rd := form.RenderData().Widgets[0]
+	fields := rd.Data.(map[string]interface{})["Fields"].([]WidgetRenderData)
+	if rd.Template != "struct" || len(fields) != 2 {
+		t.Fatalf("Invalid render data %#v", rd)
+	}
+	expected := []WidgetBase{
+		{Id: "Address.Street", Label: "Street",
+			Errors: []string{"Street required!"}},
+		{Id: "Address.City", Label: "City", Description: "Your city"},
+	}
+	for i, field := range fields {
+		if field.Id != expected[i].Id || field.Label != expected[i].Label ||
+			field.Description != expected[i].Description ||
+			!reflect.DeepEqual(field.Errors, expected[i].Errors) {
+			t.Errorf("Field %d is %#v, expected %#v", i, field.WidgetBase,
+				expected[i])
+		}
+	}
+}
+
+func TestStructWidgetInList(t *testing.T) {
+	data := struct{ Addresses []TestAddress }{}
+	form := NewForm(&data)
+	form.AddWidget(&ListWidget{InnerWidget: newTestAddressWidget()},
+		"Addresses", "", "")
+	valid := form.Fill(url.Values{
+		"Addresses.0.Street": []string{"Main St"},
+		"Addresses.0.City":   []string{"Berlin"},
+		"Addresses.1.City":   []string{"Paris"},
+	})
+	if valid {
+		t.Errorf("Fill should fail for the second address")
+	}
+	expected := []TestAddress{{"Main St", "Berlin"}, {"", "Paris"}}
+	if !reflect.DeepEqual(data.Addresses, expected) {
+		t.Errorf("Filled data is %v, expected %v", data.Addresses, expected)
+	}
+	listData := form.RenderData().Widgets[0].Data.(map[string]interface{})
+	items := listData["Items"].([]ListItemRenderData)
+	for i, errors := range [][]string{nil, []string{"Street required!"}} {
+		street := items[i].Field.Data.(map[string]interface{})["Fields"].([]WidgetRenderData)[0]
+		if street.Id != fmt.Sprintf("Addresses.%d.Street", i) ||
+			!reflect.DeepEqual(street.Errors, errors) {
+			t.Errorf("Street of item %d is %#v", i, street)
+		}
+	}
+}
+
+func TestListWidgetItemWidgets(t *testing.T) {
+	data := struct{ Tags, Other []string }{}
+	form := NewForm(&data)
+	prototype := &TextWidget{WidgetBase: WidgetBase{Label: "Tag",
+		Description: "A tag", Classes: []string{"tag"},
+		Validators: []Validator{Regexp("^..$", "Too short!")}}}
+	form.AddWidget(&ListWidget{InnerWidget: prototype}, "Tags", "", "")
+	created := 0
+	form.AddWidget(&ListWidget{NewInnerWidget: func() Widget {
+		created++
+		return new(TextWidget)
+	}}, "Other", "", "")
+	form.Fill(url.Values{
+		"Tags.0":  []string{"aa"},
+		"Tags.1":  []string{"b"},
+		"Other.0": []string{"cc"},
+	})
+	if created != 1 {
+		t.Errorf("NewInnerWidget has been called %d times, expected once",
+			created)
+	}
+	listData := form.RenderData().Widgets[0].Data.(map[string]interface{})
+	items := listData["Items"].([]ListItemRenderData)
+	expected := []WidgetBase{
+		{Id: "Tags.0", Label: "Tag", Description: "A tag",
+			Classes: []string{"tag"}},
+		{Id: "Tags.1", Label: "Tag", Description: "A tag",
+			Classes: []string{"tag"}, Errors: []string{"Too short!"}},
+	}
+	for i, item := range items {
+		base := item.Field.WidgetBase
+		base.Validators = nil
+		base.form = nil
+		if !reflect.DeepEqual(base, expected[i]) {
+			t.Errorf("Item %d is %#v, expected %#v", i, base, expected[i])
+		}
+	}
+	if prototype.Id != "" || prototype.Errors != nil {
+		t.Errorf("Prototype has been changed: %#v", prototype.WidgetBase)
+	}
+}